@@ -0,0 +1,334 @@
+package ipx
+
+import (
+	"bufio"
+	"container/heap"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net"
+	"os"
+	"sort"
+)
+
+const (
+	defaultMaxBuffered = 1 << 20
+	aggNetRecordLen    = 18 // version, prefix and 16 bytes of address
+)
+
+// AggregatorOptions configures an Aggregator.
+type AggregatorOptions struct {
+	// Sorted declares that networks will be added in the order Collapse returns them: IPv4 before IPv6, then by
+	// ascending address and, for networks with the same address, ascending prefix length. Only a handful of networks
+	// are then held at a time, and results are emitted as soon as they are known. Add returns an error if a network is
+	// out of order.
+	Sorted bool
+	// MaxBuffered is the number of unsorted networks held in memory before they are collapsed and spilled to a
+	// temporary file. Zero means 1<<20.
+	MaxBuffered int
+	// TempDir is the directory for spilled files; if empty, the default directory for temporary files is used.
+	TempDir string
+}
+
+// Aggregator collapses a stream of networks too large to hold in memory, emitting the same networks in the same order
+// as Collapse.
+type Aggregator struct {
+	opts    AggregatorOptions
+	merger  netMerger
+	buf     []aggNet
+	runs    []*os.File
+	last    aggNet
+	started bool
+}
+
+// NewAggregator returns an Aggregator passing each resulting network to emit. Returning an error from emit stops
+// aggregation, and the error is returned by the call to Add or Close which caused it.
+func NewAggregator(emit func(*net.IPNet) error, opts AggregatorOptions) *Aggregator {
+	if opts.MaxBuffered <= 0 {
+		opts.MaxBuffered = defaultMaxBuffered
+	}
+	return &Aggregator{opts: opts, merger: netMerger{emit: func(n aggNet) error {
+		return emit(n.asNet())
+	}}}
+}
+
+// Add adds a network. Host bits set in the network are ignored.
+func (a *Aggregator) Add(ipNet *net.IPNet) error {
+	n, ok := newAggNet(ipNet)
+	if !ok {
+		return errors.New("invalid network " + ipNet.String())
+	}
+	if !a.opts.Sorted {
+		a.buf = append(a.buf, n)
+		if len(a.buf) >= a.opts.MaxBuffered {
+			return a.spill()
+		}
+		return nil
+	}
+
+	if a.started && n.less(a.last) {
+		return errors.New("network " + ipNet.String() + " added out of order")
+	}
+	a.last, a.started = n, true
+	return a.merger.push(n)
+}
+
+// Close emits the remaining networks and removes any temporary files. The Aggregator may not be used afterwards.
+func (a *Aggregator) Close() error {
+	defer a.removeRuns()
+
+	sortAggNets(a.buf)
+	if len(a.runs) == 0 {
+		for _, n := range a.buf {
+			if err := a.merger.push(n); err != nil {
+				return err
+			}
+		}
+		return a.merger.flush()
+	}
+
+	// merge the spilled runs together with the networks still in memory
+	h := make(aggNetHeap, 0, len(a.runs)+1)
+	for _, f := range a.runs {
+		if _, err := f.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		src := &aggNetSource{r: bufio.NewReader(f)}
+		if err := h.pushNext(src); err != nil {
+			return err
+		}
+	}
+	if err := h.pushNext(&aggNetSource{buf: a.buf}); err != nil {
+		return err
+	}
+	for len(h) > 0 {
+		src := h[0]
+		if err := a.merger.push(src.cur); err != nil {
+			return err
+		}
+		heap.Pop(&h)
+		if err := h.pushNext(src); err != nil {
+			return err
+		}
+	}
+	return a.merger.flush()
+}
+
+// spill collapses the buffered networks into a new temporary file.
+func (a *Aggregator) spill() error {
+	f, err := ioutil.TempFile(a.opts.TempDir, "ipx-aggregate-")
+	if err != nil {
+		return err
+	}
+	a.runs = append(a.runs, f)
+
+	w := bufio.NewWriter(f)
+	var record [aggNetRecordLen]byte
+	run := netMerger{emit: func(n aggNet) error {
+		n.encode(record[:])
+		_, err := w.Write(record[:])
+		return err
+	}}
+
+	sortAggNets(a.buf)
+	for _, n := range a.buf {
+		if err := run.push(n); err != nil {
+			return err
+		}
+	}
+	if err := run.flush(); err != nil {
+		return err
+	}
+	a.buf = a.buf[:0]
+	return w.Flush()
+}
+
+func (a *Aggregator) removeRuns() {
+	for _, f := range a.runs {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}
+	a.runs = nil
+}
+
+// aggNet is a network of either IP version; IPv4 addresses occupy the low 32 bits.
+type aggNet struct {
+	six    bool
+	prefix uint8
+	addr   Uint128
+}
+
+func newAggNet(ipNet *net.IPNet) (aggNet, bool) {
+	n := Canonicalize(ipNet)
+	if n == nil {
+		return aggNet{}, false
+	}
+	ones, bits := n.Mask.Size()
+	addr, _ := IPToUint128(n.IP)
+	return aggNet{six: bits == 8*net.IPv6len, prefix: uint8(ones), addr: addr}, true
+}
+
+func (n aggNet) bits() uint8 {
+	if n.six {
+		return 8 * net.IPv6len
+	}
+	return 8 * net.IPv4len
+}
+
+// hostMask returns the bits of the addresses which vary within the network.
+func (n aggNet) hostMask() Uint128 {
+	return Uint128{0, 1}.Lsh(uint(n.bits() - n.prefix)).Minus(Uint128{0, 1})
+}
+
+func (n aggNet) last() Uint128 {
+	return n.addr.Or(n.hostMask())
+}
+
+func (n aggNet) contains(o aggNet) bool {
+	return n.six == o.six && n.prefix <= o.prefix && o.addr.AndNot(n.hostMask()) == n.addr
+}
+
+func (n aggNet) super() aggNet {
+	n.prefix--
+	n.addr = n.addr.AndNot(n.hostMask())
+	return n
+}
+
+func (n aggNet) less(o aggNet) bool {
+	if n.six != o.six {
+		return !n.six
+	}
+	if c := n.addr.Cmp(o.addr); c != 0 {
+		return c == -1
+	}
+	return n.prefix < o.prefix
+}
+
+func (n aggNet) asNet() *net.IPNet {
+	if n.six {
+		return ip6Net{addr: n.addr, prefix: n.prefix}.asNet()
+	}
+	return ip4Net{addr: uint32(n.addr.L), prefix: n.prefix}.asNet()
+}
+
+func (n aggNet) encode(b []byte) {
+	b[0] = 0
+	if n.six {
+		b[0] = 1
+	}
+	b[1] = n.prefix
+	From128(n.addr, b[2:])
+}
+
+func decodeAggNet(b []byte) aggNet {
+	return aggNet{six: b[0] == 1, prefix: b[1], addr: To128(b[2:])}
+}
+
+func sortAggNets(nets []aggNet) {
+	sort.Slice(nets, func(i, j int) bool {
+		return nets[i].less(nets[j])
+	})
+}
+
+// netMerger collapses networks pushed in sorted order, emitting results in order. It holds a stack of contiguous
+// networks, merging the top two whenever they are siblings; once a network arrives which isn't contiguous with the
+// stack, no network on the stack can grow any further.
+type netMerger struct {
+	stack []aggNet
+	emit  func(aggNet) error
+}
+
+func (m *netMerger) push(n aggNet) error {
+	if len(m.stack) > 0 {
+		top := m.stack[len(m.stack)-1]
+		if top.contains(n) {
+			return nil
+		}
+		next, overflow := top.last().AddOverflow(Uint128{0, 1})
+		if top.six != n.six || overflow || next != n.addr {
+			if err := m.flush(); err != nil {
+				return err
+			}
+		}
+	}
+
+	m.stack = append(m.stack, n)
+	for len(m.stack) >= 2 {
+		a, b := m.stack[len(m.stack)-2], m.stack[len(m.stack)-1]
+		if a.prefix != b.prefix || a.prefix == 0 || a.super() != b.super() {
+			break
+		}
+		m.stack = append(m.stack[:len(m.stack)-2], a.super())
+	}
+	return nil
+}
+
+func (m *netMerger) flush() error {
+	for _, n := range m.stack {
+		if err := m.emit(n); err != nil {
+			return err
+		}
+	}
+	m.stack = m.stack[:0]
+	return nil
+}
+
+// aggNetSource yields sorted networks from either a spilled file or memory.
+type aggNetSource struct {
+	r   *bufio.Reader
+	buf []aggNet
+	cur aggNet
+}
+
+func (s *aggNetSource) next() (bool, error) {
+	if s.r == nil {
+		if len(s.buf) == 0 {
+			return false, nil
+		}
+		s.cur, s.buf = s.buf[0], s.buf[1:]
+		return true, nil
+	}
+
+	var record [aggNetRecordLen]byte
+	if _, err := io.ReadFull(s.r, record[:]); err == io.EOF {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+	s.cur = decodeAggNet(record[:])
+	return true, nil
+}
+
+type aggNetHeap []*aggNetSource
+
+func (h aggNetHeap) Len() int {
+	return len(h)
+}
+
+func (h aggNetHeap) Less(i, j int) bool {
+	return h[i].cur.less(h[j].cur)
+}
+
+func (h aggNetHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+}
+
+func (h *aggNetHeap) Push(x interface{}) {
+	*h = append(*h, x.(*aggNetSource))
+}
+
+func (h *aggNetHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// pushNext advances the source and pushes it back onto the heap if it isn't exhausted.
+func (h *aggNetHeap) pushNext(src *aggNetSource) error {
+	ok, err := src.next()
+	if ok {
+		heap.Push(h, src)
+	}
+	return err
+}
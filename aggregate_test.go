@@ -0,0 +1,156 @@
+package ipx_test
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ns1/ipx"
+	"io/ioutil"
+	"math/rand"
+	"net"
+	"os"
+	"sort"
+	"testing"
+)
+
+func ExampleAggregator() {
+	a := ipx.NewAggregator(func(n *net.IPNet) error {
+		fmt.Println(n)
+		return nil
+	}, ipx.AggregatorOptions{})
+	for _, s := range []string{"192.0.2.128/25", "2001:db8::/32", "192.0.2.0/25", "10.0.0.0/8", "10.1.0.0/16"} {
+		_ = a.Add(cidr(s))
+	}
+	_ = a.Close()
+	// Output:
+	// 10.0.0.0/8
+	// 192.0.2.0/24
+	// 2001:db8::/32
+}
+
+func TestAggregatorMatchesCollapse(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		nets := randomNets(r, 1+r.Intn(300))
+		expected := fmt.Sprint(ipx.Collapse(nets))
+
+		sorted := append([]*net.IPNet(nil), nets...)
+		sort.Slice(sorted, func(i, j int) bool {
+			a, b := sorted[i], sorted[j]
+			if aFour, bFour := a.IP.To4() != nil, b.IP.To4() != nil; aFour != bFour {
+				return aFour
+			}
+			if c := ipx.CmpIP(a.IP.Mask(a.Mask), b.IP.Mask(b.Mask)); c != 0 {
+				return c == -1
+			}
+			aOnes, _ := a.Mask.Size()
+			bOnes, _ := b.Mask.Size()
+			return aOnes < bOnes
+		})
+
+		for _, c := range []struct {
+			name string
+			nets []*net.IPNet
+			opts ipx.AggregatorOptions
+		}{
+			{"unsorted", nets, ipx.AggregatorOptions{}},
+			{"spilled", nets, ipx.AggregatorOptions{MaxBuffered: 1 + r.Intn(50), TempDir: dir}},
+			{"sorted", sorted, ipx.AggregatorOptions{Sorted: true}},
+		} {
+			var got []*net.IPNet
+			a := ipx.NewAggregator(func(n *net.IPNet) error {
+				got = append(got, n)
+				return nil
+			}, c.opts)
+			for _, n := range c.nets {
+				if err := a.Add(n); err != nil {
+					t.Fatalf("%v: unexpected error: %v", c.name, err)
+				}
+			}
+			if err := a.Close(); err != nil {
+				t.Fatalf("%v: unexpected error: %v", c.name, err)
+			}
+			if files, _ := ioutil.ReadDir(dir); len(files) != 0 {
+				t.Fatalf("%v: expected spilled files to be removed but found %v", c.name, len(files))
+			}
+			if fmt.Sprint(got) != expected {
+				t.Fatalf("%v: for %v expected %v but got %v", c.name, nets, expected, got)
+			}
+		}
+	}
+}
+
+// randomNets returns networks of both versions clustered closely enough to overlap and merge.
+func randomNets(r *rand.Rand, n int) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, n)
+	for i := 0; i < n; i++ {
+		if r.Intn(4) == 0 {
+			ip := net.ParseIP("2001:db8::")
+			ip[15] = byte(r.Intn(64))
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(120+r.Intn(9), 128)})
+			continue
+		}
+		ip := net.IPv4(10, 0, byte(r.Intn(2)), byte(r.Intn(256))).To4()
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(22+r.Intn(11), 32)})
+	}
+	return nets
+}
+
+func TestAggregatorEdges(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		in       []string
+		expected string
+	}{
+		{"empty", nil, "[]"},
+		{"everything", []string{"0.0.0.0/1", "128.0.0.0/1", "::/1", "8000::/1"}, "[0.0.0.0/0 ::/0]"},
+		{"top of space", []string{"255.255.255.254/32", "255.255.255.255/32", "0.0.0.0/32"}, "[0.0.0.0/32 255.255.255.254/31]"},
+		{"contained", []string{"10.0.0.0/8", "10.0.0.0/16", "10.255.255.255/32"}, "[10.0.0.0/8]"},
+		{"v4 mapped", []string{"::ffff:10.0.0.0/104", "10.0.0.0/8"}, "[10.0.0.0/8]"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var got []*net.IPNet
+			a := ipx.NewAggregator(func(n *net.IPNet) error {
+				got = append(got, n)
+				return nil
+			}, ipx.AggregatorOptions{})
+			for _, s := range c.in {
+				_, n, _ := net.ParseCIDR(s)
+				if err := a.Add(n); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if err := a.Close(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if fmt.Sprint(got) != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestAggregatorErrors(t *testing.T) {
+	a := ipx.NewAggregator(func(*net.IPNet) error { return nil }, ipx.AggregatorOptions{Sorted: true})
+	if err := a.Add(cidr("10.0.1.0/24")); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := a.Add(cidr("10.0.0.0/24")); err == nil {
+		t.Fatal("expected error for out of order network")
+	}
+	if err := a.Add(&net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.IPv4Mask(255, 0, 255, 0)}); err == nil {
+		t.Fatal("expected error for invalid mask")
+	}
+
+	stop := errors.New("stop")
+	a = ipx.NewAggregator(func(*net.IPNet) error { return stop }, ipx.AggregatorOptions{Sorted: true})
+	_ = a.Add(cidr("10.0.0.0/24"))
+	if err := a.Add(cidr("10.0.2.0/24")); err != stop {
+		t.Fatalf("expected emit's error but got %v", err)
+	}
+}
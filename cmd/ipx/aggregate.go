@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/ns1/ipx"
+	"io"
+	"net"
+	"os"
+)
+
+// runAggregate reads networks from the named files, or stdin if there are none, and prints the fewest networks
+// covering the same addresses, one per line, as the classic aggregate utility does.
+func runAggregate(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("aggregate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	var opts ipx.AggregatorOptions
+	flags.BoolVar(&opts.Sorted, "sorted", false, "the input is already sorted, so hold only a handful of networks in memory")
+	flags.IntVar(&opts.MaxBuffered, "buffer", 0, "hold up to `n` unsorted networks in memory before spilling to disk (default 1048576)")
+	flags.StringVar(&opts.TempDir, "tmp", "", "spill to files in `dir`")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: ipx aggregate [-sorted] [-buffer n] [-tmp dir] [file ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	out := bufio.NewWriter(stdout)
+	a := ipx.NewAggregator(func(n *net.IPNet) error {
+		_, err := fmt.Fprintln(out, n)
+		return err
+	}, opts)
+
+	if err := aggregate(a, flags.Args(), stdin); err != nil {
+		_ = a.Close()
+		fmt.Fprintf(stderr, "ipx aggregate: %v\n", err)
+		return 2
+	}
+	if err := a.Close(); err != nil {
+		fmt.Fprintf(stderr, "ipx aggregate: %v\n", err)
+		return 2
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(stderr, "ipx aggregate: %v\n", err)
+		return 2
+	}
+	return 0
+}
+
+func aggregate(a *ipx.Aggregator, files []string, stdin io.Reader) error {
+	if len(files) == 0 {
+		return scanNets(stdin, a.Add)
+	}
+	for _, name := range files {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		err = scanNets(f, a.Add)
+		_ = f.Close()
+		if err != nil {
+			return fmt.Errorf("%v: %v", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestAggregate(t *testing.T) {
+	const in = `# blocklist
+192.0.2.128/25
+192.0.2.0/25
+2001:db8::/33
+
+10.0.0.0 255.0.0.0
+10.1.2.3/16
+2001:db8:8000::/33
+`
+	for _, c := range []struct {
+		name     string
+		args     []string
+		in       string
+		expected string
+		status   int
+	}{
+		{"unsorted", nil, in, "10.0.0.0/8\n192.0.2.0/24\n2001:db8::/32\n", 0},
+		{"spilled", []string{"-buffer", "2"}, in, "10.0.0.0/8\n192.0.2.0/24\n2001:db8::/32\n", 0},
+		{"sorted", []string{"-sorted"}, "10.0.0.0/25\n10.0.0.128/25\n10.0.2.0/24\n", "10.0.0.0/24\n10.0.2.0/24\n", 0},
+		{"out of order", []string{"-sorted"}, "10.0.2.0/24\n10.0.0.0/24\n", "", 2},
+		{"bad network", nil, "10.0.0.0/8\nnope\n", "", 2},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(append([]string{"aggregate"}, c.args...), strings.NewReader(c.in), &stdout, &stderr)
+			if status != c.status {
+				t.Fatalf("expected status %v but got %v: %v", c.status, status, stderr.String())
+			}
+			if stdout.String() != c.expected {
+				t.Fatalf("expected %q but got %q", c.expected, stdout.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"github.com/ns1/ipx"
+	"io"
+	"net"
+	"os"
+	"strings"
+)
+
+// runGrep prints the lines of stdin containing an address in the networks given as arguments or read from a file.
+// Like grep, it exits with 0 when a line is printed, 1 when none are and 2 on error.
+func runGrep(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("grep", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	invert := flags.Bool("v", false, "print lines not containing an address in the networks")
+	file := flags.String("f", "", "read networks from `file`, one per line; blank lines and #-comments are ignored")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: ipx grep [-v] [-f file] [network ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var nets []*net.IPNet
+	for _, s := range flags.Args() {
+		_, n, err := ipx.ParseNetLenient(s)
+		if err != nil {
+			fmt.Fprintf(stderr, "ipx grep: %v\n", err)
+			return 2
+		}
+		nets = append(nets, n)
+	}
+	if *file != "" {
+		fromFile, err := readNets(*file)
+		if err != nil {
+			fmt.Fprintf(stderr, "ipx grep: %v\n", err)
+			return 2
+		}
+		nets = append(nets, fromFile...)
+	}
+	if len(nets) == 0 {
+		flags.Usage()
+		return 2
+	}
+	set := ipx.NewNetSet(nets)
+
+	var (
+		in      = bufio.NewReader(stdin)
+		out     = bufio.NewWriter(stdout)
+		line    = strings.NewReader("")
+		scanner = ipx.NewAddrScanner(line)
+		printed bool
+	)
+	for {
+		text, err := in.ReadString('\n')
+		if text != "" {
+			line.Reset(text)
+			scanner.Reset(line)
+			if containsAddr(scanner, set) != *invert {
+				printed = true
+				if _, err := out.WriteString(text); err != nil {
+					fmt.Fprintf(stderr, "ipx grep: %v\n", err)
+					return 2
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			fmt.Fprintf(stderr, "ipx grep: %v\n", err)
+			return 2
+		}
+	}
+	if err := out.Flush(); err != nil {
+		fmt.Fprintf(stderr, "ipx grep: %v\n", err)
+		return 2
+	}
+	if !printed {
+		return 1
+	}
+	return 0
+}
+
+// containsAddr returns whether any address found by the scanner is in the set. Networks and ranges are matched by
+// the address they are written with.
+func containsAddr(scanner *ipx.AddrScanner, set *ipx.NetSet) bool {
+	for scanner.Scan() {
+		if set.Contains(scanner.Match().IP) {
+			return true
+		}
+	}
+	return false
+}
+
+// readNets reads networks from the named file as scanNets does.
+func readNets(name string) ([]*net.IPNet, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var nets []*net.IPNet
+	err = scanNets(f, func(n *net.IPNet) error {
+		nets = append(nets, n)
+		return nil
+	})
+	return nets, err
+}
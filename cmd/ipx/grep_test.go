@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const accessLog = `192.0.2.1 - - "GET / HTTP/1.1" 200
+198.51.100.7 - - "GET /a HTTP/1.1" 404
+client=[2001:db8::1]:443 ok
+no address here
+proxy 203.0.113.5 for 192.0.2.200
+src_ip:192.0.2.9 dst:2001:db8::2
+host:198.51.100.9
+`
+
+func TestGrep(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ipx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "nets")
+	if err := ioutil.WriteFile(file, []byte("# documentation\n192.0.2.0/24\n\n2001:db8::/32 # v6\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		name     string
+		args     []string
+		expected string
+		status   int
+	}{
+		{"args", []string{"192.0.2.0/24"}, "192.0.2.1 - - \"GET / HTTP/1.1\" 200\nproxy 203.0.113.5 for 192.0.2.200\nsrc_ip:192.0.2.9 dst:2001:db8::2\n", 0},
+		{"invert", []string{"-v", "192.0.2.0/24", "2001:db8::/32"}, "198.51.100.7 - - \"GET /a HTTP/1.1\" 404\nno address here\nhost:198.51.100.9\n", 0},
+		{"key", []string{"198.51.100.9/32"}, "host:198.51.100.9\n", 0},
+		{"invert key", []string{"-v", "192.0.2.0/24", "198.51.100.0/24"}, "client=[2001:db8::1]:443 ok\nno address here\n", 0},
+		{"file", []string{"-f", file, "203.0.113.0 255.255.255.0"}, "192.0.2.1 - - \"GET / HTTP/1.1\" 200\nclient=[2001:db8::1]:443 ok\nproxy 203.0.113.5 for 192.0.2.200\nsrc_ip:192.0.2.9 dst:2001:db8::2\n", 0},
+		{"none", []string{"10.0.0.0/8"}, "", 1},
+		{"no networks", nil, "", 2},
+		{"bad network", []string{"10.0.0.0/33"}, "", 2},
+		{"missing file", []string{"-f", filepath.Join(dir, "missing")}, "", 2},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+			status := run(append([]string{"grep"}, c.args...), strings.NewReader(accessLog), &stdout, &stderr)
+			if status != c.status {
+				t.Fatalf("expected status %v but got %v: %v", c.status, status, stderr.String())
+			}
+			if stdout.String() != c.expected {
+				t.Fatalf("expected %q but got %q", c.expected, stdout.String())
+			}
+		})
+	}
+}
+
+func TestRunUnknownCommand(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	if status := run([]string{"frobnicate"}, strings.NewReader(""), &stdout, &stderr); status != 2 {
+		t.Fatalf("expected status 2 but got %v", status)
+	}
+	if !strings.Contains(stderr.String(), "grep") {
+		t.Fatalf("expected usage listing commands but got %q", stderr.String())
+	}
+}
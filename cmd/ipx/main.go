@@ -0,0 +1,85 @@
+// Command ipx exposes some of the ipx package's functionality on the command line.
+//
+// Usage:
+//
+//	ipx <command> [arguments]
+//
+// The commands are:
+//
+//	aggregate  collapse networks into the fewest networks covering the same addresses
+//	grep       print lines containing addresses in (or with -v, not in) a set of networks
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/ns1/ipx"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// command runs with the arguments following its name and returns the exit status.
+type command func(args []string, stdin io.Reader, stdout, stderr io.Writer) int
+
+var commands = map[string]command{
+	"aggregate": runAggregate,
+	"grep":      runGrep,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return 2
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "ipx: unknown command %q\n", args[0])
+		usage(stderr)
+		return 2
+	}
+	return cmd(args[1:], stdin, stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(w, "usage: ipx <command> [arguments]")
+	fmt.Fprintln(w, "commands:")
+	for _, name := range names {
+		fmt.Fprintln(w, "\t"+name)
+	}
+}
+
+// scanNets calls fn with each network read from r, one per line in any form accepted by ipx.ParseNetLenient. Blank
+// lines and comments starting with # are ignored.
+func scanNets(r io.Reader, fn func(*net.IPNet) error) error {
+	s := bufio.NewScanner(r)
+	for line := 1; s.Scan(); line++ {
+		text := s.Text()
+		if i := strings.IndexByte(text, '#'); i >= 0 {
+			text = text[:i]
+		}
+		if text = strings.TrimSpace(text); text == "" {
+			continue
+		}
+		_, n, err := ipx.ParseNetLenient(text)
+		if err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+		if err := fn(n); err != nil {
+			return fmt.Errorf("line %d: %v", line, err)
+		}
+	}
+	return s.Err()
+}
@@ -5,108 +5,119 @@ import (
 	"sort"
 )
 
-// Collapse combines subnets into their closest available parent.
+// Collapse combines subnets into their closest available parent. Host bits set in any of the networks are ignored.
 func Collapse(toMerge []*net.IPNet) []*net.IPNet {
-	var (
-		four []ip4Net
-		six  []ip6Net
-	)
-	for _, ipN := range toMerge {
+	return AppendCollapse(nil, toMerge)
+}
+
+// AppendCollapse appends the result of collapsing src to dst, as Collapse does, and returns the extended slice.
+// Networks left in dst's spare capacity by an earlier call are reused rather than allocated, so dst's spare capacity
+// must not hold networks which are still in use. Their IPs and masks are replaced rather than overwritten, since the
+// callers' networks may share them; the results' IPs and masks share a single allocation. src may share dst's storage,
+// e.g. AppendCollapse(nets[:0], nets).
+func AppendCollapse(dst, src []*net.IPNet) []*net.IPNet {
+	numSix := 0
+	for _, ipN := range src {
+		if ipN.IP.To4() == nil {
+			numSix++
+		}
+	}
+	four, six := make([]ip4Net, 0, len(src)-numSix), make([]ip6Net, 0, numSix)
+	for _, ipN := range src {
 		if ipN.IP.To4() != nil {
 			four = append(four, newIP4Net(ipN))
 			continue
 		}
 		six = append(six, newIP6Net(ipN))
 	}
-	return append(collapse4(four), collapse6(six)...)
+	four, six = collapse4(four), collapse6(six)
+
+	start := len(dst)
+	dst = growNets(dst, len(four)+len(six))
+	b := make([]byte, 2*(net.IPv4len*len(four)+net.IPv6len*len(six)))
+	for i, n := range four {
+		r := dst[start+i]
+		r.IP, r.Mask, b = sliceNet(b, net.IPv4len)
+		from32(n.addr, r.IP)
+		from32(n.mask(), r.Mask)
+	}
+	for i, n := range six {
+		r := dst[start+len(four)+i]
+		r.IP, r.Mask, b = sliceNet(b, net.IPv6len)
+		From128(n.addr, r.IP)
+		From128(n.mask(), r.Mask)
+	}
+	return dst
 }
 
-func collapse4(nets []ip4Net) []*net.IPNet {
-	if len(nets) == 0 {
-		return nil
+// growNets extends dst by n networks, reusing those in its spare capacity and allocating the rest together.
+func growNets(dst []*net.IPNet, n int) []*net.IPNet {
+	start := len(dst)
+	if start+n > cap(dst) {
+		dst = append(dst[:cap(dst)], make([]*net.IPNet, start+n-cap(dst))...)
 	}
+	dst = dst[:start+n]
 
-	supers := make(map[ip4Net]ip4Net)
-	for len(nets) > 0 {
-		n := nets[len(nets)-1]
-		nets = nets[:len(nets)-1]
-
-		s := n.super()
-		other, ok := supers[s]
-		if !ok {
-			supers[s] = n
-			continue
+	missing := 0
+	for _, r := range dst[start:] {
+		if r == nil {
+			missing++
 		}
-		if other == n {
-			continue
-		}
-
-		// we have found two nets with same immediate parent -- merge 'em
-		delete(supers, s)
-		nets = append(nets, s)
 	}
-
-	merged := make(ip4Nets, 0, len(supers))
-	for _, v := range supers {
-		merged = append(merged, v)
-	}
-	sort.Sort(merged)
-
-	result := []*net.IPNet{merged[0].asNet()}
-	lastMask := merged[0].mask()
-	lastAddr := merged[0].addr
-	for _, m := range merged[1:] {
-		if lastAddr == m.addr&lastMask {
-			continue
+	nets := make([]net.IPNet, missing)
+	for i, r := range dst[start:] {
+		if r == nil {
+			dst[start+i], nets = &nets[0], nets[1:]
 		}
-		result = append(result, m.asNet())
-		lastMask, lastAddr = m.mask(), m.addr
 	}
-	return result
+	return dst
 }
 
-func collapse6(nets []ip6Net) []*net.IPNet {
-	if len(nets) == 0 {
-		return nil
-	}
-
-	supers := make(map[ip6Net]ip6Net)
-	for len(nets) > 0 {
-		n := nets[len(nets)-1]
-		nets = nets[:len(nets)-1]
+// sliceNet returns an IP and mask of the given length from the start of b, and the rest of b.
+func sliceNet(b []byte, size int) (net.IP, net.IPMask, []byte) {
+	return net.IP(b[:size:size]), net.IPMask(b[size : 2*size : 2*size]), b[2*size:]
+}
 
-		s := n.super()
-		other, ok := supers[s]
-		if !ok {
-			supers[s] = n
+// collapse4 sorts nets and merges them in place, returning the collapsed networks in ascending order. Sorted by address
+// and then prefix length, a network can only be covered by the last network kept so far, and the kept networks form a
+// stack in which a new network can only merge with its sibling at the top.
+func collapse4(nets []ip4Net) []ip4Net {
+	sort.Sort(ip4Nets(nets))
+	merged := nets[:0]
+	for _, n := range nets {
+		if len(merged) > 0 && n.subnetOf(merged[len(merged)-1]) {
 			continue
 		}
-		if other == n {
-			continue
+		merged = append(merged, n)
+		for len(merged) >= 2 {
+			a, b := merged[len(merged)-2], merged[len(merged)-1]
+			if a.prefix != b.prefix || a.prefix == 0 || a.super() != b.super() {
+				break
+			}
+			merged = append(merged[:len(merged)-2], a.super())
 		}
-
-		// we have found two nets with same immediate parent -- merge 'em
-		delete(supers, s)
-		nets = append(nets, s)
 	}
+	return merged
+}
 
-	merged := make(ip6Nets, 0, len(supers))
-	for _, v := range supers {
-		merged = append(merged, v)
-	}
-	sort.Sort(merged)
-
-	result := []*net.IPNet{merged[0].asNet()}
-	lastMask := merged[0].mask()
-	lastAddr := merged[0].addr
-	for _, m := range merged[1:] {
-		if lastAddr == m.addr.And(lastMask) {
+// collapse6 is collapse4 for IPv6 networks.
+func collapse6(nets []ip6Net) []ip6Net {
+	sort.Sort(ip6Nets(nets))
+	merged := nets[:0]
+	for _, n := range nets {
+		if len(merged) > 0 && n.subnetOf(merged[len(merged)-1]) {
 			continue
 		}
-		result = append(result, m.asNet())
-		lastMask, lastAddr = m.mask(), m.addr
+		merged = append(merged, n)
+		for len(merged) >= 2 {
+			a, b := merged[len(merged)-2], merged[len(merged)-1]
+			if a.prefix != b.prefix || a.prefix == 0 || a.super() != b.super() {
+				break
+			}
+			merged = append(merged[:len(merged)-2], a.super())
+		}
 	}
-	return result
+	return merged
 }
 
 type ip4Net struct {
@@ -116,7 +127,9 @@ type ip4Net struct {
 
 func newIP4Net(ipN *net.IPNet) ip4Net {
 	ones, _ := ipN.Mask.Size()
-	return ip4Net{to32(ipN.IP), uint8(ones)}
+	n := ip4Net{prefix: uint8(ones)}
+	n.addr = to32(ipN.IP) & n.mask()
+	return n
 }
 
 func (n ip4Net) super() ip4Net {
@@ -153,7 +166,8 @@ func (n ip4Nets) Len() int {
 }
 
 func (n ip4Nets) Less(i, j int) bool {
-	return n[i].addr < n[j].addr
+	// shorter prefixes sort first so that they absorb longer ones sharing the same address
+	return n[i].addr < n[j].addr || (n[i].addr == n[j].addr && n[i].prefix < n[j].prefix)
 }
 
 func (n ip4Nets) Swap(i, j int) {
@@ -167,7 +181,9 @@ type ip6Net struct {
 
 func newIP6Net(ipN *net.IPNet) ip6Net {
 	ones, _ := ipN.Mask.Size()
-	return ip6Net{To128(ipN.IP), uint8(ones)}
+	n := ip6Net{prefix: uint8(ones)}
+	n.addr = To128(ipN.IP).And(n.mask())
+	return n
 }
 
 func (n ip6Net) super() ip6Net {
@@ -205,7 +221,8 @@ func (n ip6Nets) Len() int {
 }
 
 func (n ip6Nets) Less(i, j int) bool {
-	return n[i].addr.Cmp(n[j].addr) == -1
+	c := n[i].addr.Cmp(n[j].addr)
+	return c == -1 || (c == 0 && n[i].prefix < n[j].prefix)
 }
 
 func (n ip6Nets) Swap(i, j int) {
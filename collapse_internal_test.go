@@ -0,0 +1,242 @@
+package ipx
+
+import (
+	"fmt"
+	"math/rand"
+	"net"
+	"sort"
+	"testing"
+)
+
+// collapseMap is the original implementation of Collapse, merging through a map of supernets. It is kept to check
+// and benchmark the current implementation against.
+func collapseMap(toMerge []*net.IPNet) []*net.IPNet {
+	var (
+		four []ip4Net
+		six  []ip6Net
+	)
+	for _, ipN := range toMerge {
+		if ipN.IP.To4() != nil {
+			four = append(four, newIP4Net(ipN))
+			continue
+		}
+		six = append(six, newIP6Net(ipN))
+	}
+	return append(collapseMap4(four), collapseMap6(six)...)
+}
+
+func collapseMap4(nets []ip4Net) []*net.IPNet {
+	if len(nets) == 0 {
+		return nil
+	}
+
+	supers := make(map[ip4Net]ip4Net)
+	for len(nets) > 0 {
+		n := nets[len(nets)-1]
+		nets = nets[:len(nets)-1]
+
+		s := n.super()
+		other, ok := supers[s]
+		if !ok {
+			supers[s] = n
+			continue
+		}
+		if other == n {
+			continue
+		}
+
+		// we have found two nets with same immediate parent -- merge 'em
+		delete(supers, s)
+		nets = append(nets, s)
+	}
+
+	merged := make(ip4Nets, 0, len(supers))
+	for _, v := range supers {
+		merged = append(merged, v)
+	}
+	sort.Sort(merged)
+
+	result := []*net.IPNet{merged[0].asNet()}
+	lastMask := merged[0].mask()
+	lastAddr := merged[0].addr
+	for _, m := range merged[1:] {
+		if lastAddr == m.addr&lastMask {
+			continue
+		}
+		result = append(result, m.asNet())
+		lastMask, lastAddr = m.mask(), m.addr
+	}
+	return result
+}
+
+func collapseMap6(nets []ip6Net) []*net.IPNet {
+	if len(nets) == 0 {
+		return nil
+	}
+
+	supers := make(map[ip6Net]ip6Net)
+	for len(nets) > 0 {
+		n := nets[len(nets)-1]
+		nets = nets[:len(nets)-1]
+
+		s := n.super()
+		other, ok := supers[s]
+		if !ok {
+			supers[s] = n
+			continue
+		}
+		if other == n {
+			continue
+		}
+
+		// we have found two nets with same immediate parent -- merge 'em
+		delete(supers, s)
+		nets = append(nets, s)
+	}
+
+	merged := make(ip6Nets, 0, len(supers))
+	for _, v := range supers {
+		merged = append(merged, v)
+	}
+	sort.Sort(merged)
+
+	result := []*net.IPNet{merged[0].asNet()}
+	lastMask := merged[0].mask()
+	lastAddr := merged[0].addr
+	for _, m := range merged[1:] {
+		if lastAddr == m.addr.And(lastMask) {
+			continue
+		}
+		result = append(result, m.asNet())
+		lastMask, lastAddr = m.mask(), m.addr
+	}
+	return result
+}
+
+func TestCollapseMatchesCollapseMap(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		nets := randomCollapseNets(r, 1+r.Intn(200))
+		expected := fmt.Sprint(collapseMap(append([]*net.IPNet(nil), nets...)))
+		if got := fmt.Sprint(Collapse(nets)); got != expected {
+			t.Fatalf("for %v expected %v but got %v", nets, expected, got)
+		}
+	}
+}
+
+func TestAppendCollapseReuse(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	var dst []*net.IPNet
+	for i := 0; i < 100; i++ {
+		nets := randomCollapseNets(r, 1+r.Intn(100))
+		expected := fmt.Sprint(collapseMap(nets))
+
+		// reuse both the slice and the networks left in it by the last call
+		dst = AppendCollapse(dst[:0], nets)
+		if got := fmt.Sprint(dst); got != expected {
+			t.Fatalf("for %v expected %v but got %v", nets, expected, got)
+		}
+
+		// collapse in place
+		inPlace := AppendCollapse(nets[:0], nets)
+		if got := fmt.Sprint(inPlace); got != expected {
+			t.Fatalf("in place for %v expected %v but got %v", nets, expected, got)
+		}
+	}
+
+	// networks built with one mask value mustn't see it overwritten as the result is written
+	mask := net.CIDRMask(24, 32)
+	shared := []*net.IPNet{
+		{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: mask},
+		{IP: net.IPv4(10, 0, 1, 0).To4(), Mask: mask},
+		{IP: net.IPv4(10, 0, 4, 0).To4(), Mask: mask},
+	}
+	if got := fmt.Sprint(AppendCollapse(shared[:0], shared)); got != "[10.0.0.0/23 10.0.4.0/24]" {
+		t.Fatalf("expected [10.0.0.0/23 10.0.4.0/24] in place with a shared mask but got %v", got)
+	}
+
+	prefix := []*net.IPNet{{IP: net.IPv4(192, 0, 2, 0).To4(), Mask: net.CIDRMask(24, 32)}}
+	got := AppendCollapse(prefix, []*net.IPNet{{IP: net.ParseIP("10.0.0.0"), Mask: net.CIDRMask(8, 32)}})
+	if fmt.Sprint(got) != "[192.0.2.0/24 10.0.0.0/8]" {
+		t.Fatalf("expected dst's networks to be kept but got %v", got)
+	}
+}
+
+func TestAppendCollapseAllocations(t *testing.T) {
+	nets := randomCollapseNets(rand.New(rand.NewSource(3)), 1000)
+	dst := AppendCollapse(nil, nets)
+	// the networks of each version, their sorts and the results' IPs and masks, however many networks there are
+	if allocs := testing.AllocsPerRun(100, func() { dst = AppendCollapse(dst[:0], nets) }); allocs > 5 {
+		t.Fatalf("expected at most 5 allocations reusing dst but got %v", allocs)
+	}
+	// and one more for the results' networks
+	if allocs := testing.AllocsPerRun(100, func() { AppendCollapse(make([]*net.IPNet, 0, len(dst)), nets) }); allocs > 6 {
+		t.Fatalf("expected at most 6 allocations but got %v", allocs)
+	}
+}
+
+// randomCollapseNets returns networks of both versions clustered closely enough to overlap and merge.
+func randomCollapseNets(r *rand.Rand, n int) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, n)
+	for i := 0; i < n; i++ {
+		if r.Intn(4) == 0 {
+			ip := make(net.IP, net.IPv6len)
+			ip[0], ip[14], ip[15] = byte(r.Intn(2)<<7), byte(r.Intn(2)), byte(r.Intn(256))
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(118+r.Intn(11), 128)})
+			continue
+		}
+		ip := net.IPv4(byte(r.Intn(2)<<7), 0, byte(r.Intn(4)), byte(r.Intn(256))).To4()
+		ones := 21 + r.Intn(12)
+		if r.Intn(100) == 0 {
+			ones = r.Intn(2)
+		}
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(ones, 32)})
+	}
+	return nets
+}
+
+// benchmarkNets returns a route table like set of networks, about half of which can be merged.
+func benchmarkNets(n int) []*net.IPNet {
+	r := rand.New(rand.NewSource(1))
+	nets := make([]*net.IPNet, 0, n)
+	for len(nets) < n {
+		ones := 16 + r.Intn(9)
+		ip := make(net.IP, net.IPv4len)
+		from32(r.Uint32()&^(1<<uint(32-ones)-1), ip)
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(ones, 32)})
+		if r.Intn(2) == 0 { // add its sibling
+			sib := make(net.IP, net.IPv4len)
+			from32(to32(ip)^1<<uint(32-ones), sib)
+			nets = append(nets, &net.IPNet{IP: sib, Mask: net.CIDRMask(ones, 32)})
+		}
+	}
+	sort.Slice(nets, func(i, j int) bool { // route tables are usually sorted
+		return to32(nets[i].IP) < to32(nets[j].IP)
+	})
+	return nets
+}
+
+func BenchmarkCollapse(b *testing.B) {
+	for _, n := range []int{1000, 100000} {
+		nets := benchmarkNets(n)
+		b.Run(fmt.Sprintf("map/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				collapseMap(nets)
+			}
+		})
+		b.Run(fmt.Sprintf("sort/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				Collapse(nets)
+			}
+		})
+		b.Run(fmt.Sprintf("append/%d", n), func(b *testing.B) {
+			b.ReportAllocs()
+			var dst []*net.IPNet
+			for i := 0; i < b.N; i++ {
+				dst = AppendCollapse(dst[:0], nets)
+			}
+		})
+	}
+}
@@ -52,6 +52,16 @@ func TestCollapse(t *testing.T) {
 			[]string{"0:80::/26", "0:c0::/26", "192.0.2.0/26", "192.0.2.64/26"},
 			[]string{"192.0.2.0/25", "0:80::/25"},
 		},
+		{
+			"merged and given parent share address",
+			[]string{"10.0.0.1/32", "10.0.0.0/32", "10.0.0.2/32", "10.0.0.3/32", "10.0.0.0/31"},
+			[]string{"10.0.0.0/30"},
+		},
+		{
+			"merged and given parent share address v6",
+			[]string{"::1/128", "::/128", "::2/128", "::3/128", "::/127"},
+			[]string{"::/126"},
+		},
 		{
 			"ipv4 child included",
 			[]string{"192.0.2.0/26", "192.0.2.64/26", "192.0.2.64/27"},
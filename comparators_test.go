@@ -8,7 +8,7 @@ import (
 	"github.com/ns1/ipx"
 )
 
-func ExampleCmpIP_IP4() {
+func ExampleCmpIP_ip4() {
 	a := net.ParseIP("192.168.0.10")
 	b := net.ParseIP("192.168.0.20")
 	fmt.Println(ipx.CmpIP(a, b))
@@ -16,7 +16,7 @@ func ExampleCmpIP_IP4() {
 	// -1
 }
 
-func ExampleCmpIP_IP6() {
+func ExampleCmpIP_ip6() {
 	a := net.ParseIP("2001:db8::1")
 	b := net.ParseIP("2001:db8::cafe")
 	fmt.Println(ipx.CmpIP(a, b))
@@ -24,7 +24,7 @@ func ExampleCmpIP_IP6() {
 	// -1
 }
 
-func ExampleCmpNet_IP4() {
+func ExampleCmpNet_ip4() {
 	a := cidr("192.168.0.10/24")
 	b := cidr("192.168.0.10/16")
 	fmt.Println(ipx.CmpNet(a, b))
@@ -32,7 +32,7 @@ func ExampleCmpNet_IP4() {
 	// 0
 }
 
-func ExampleCmpNet_IP6() {
+func ExampleCmpNet_ip6() {
 	a := cidr("2001:db8::1/128")
 	b := cidr("2001:db8::1/64")
 	fmt.Println(ipx.CmpNet(a, b))
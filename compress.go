@@ -0,0 +1,358 @@
+package ipx
+
+import (
+	"container/heap"
+	"net"
+)
+
+// Compress returns at most maxEntries networks covering every address in nets, choosing the networks to cover as few
+// other addresses as possible. The least wasteful merges are found for every subtree of a binary trie of the
+// networks, so the time and memory taken grow with the number of networks times maxEntries. IPv4 and IPv6 networks
+// are never merged together, so at least one network of each version present is returned. The result is ordered as
+// Collapse orders it.
+func Compress(nets []*net.IPNet, maxEntries int) []*net.IPNet {
+	c := newCompressor(nets)
+	if c.entries > maxEntries {
+		c.mergeBest(maxEntries)
+	}
+	return c.result()
+}
+
+// CompressWaste merges networks greedily into their common supernets for as long as the total number of addresses
+// added stays within maxWaste. The cheapest merges are taken first, where the cost of a merge is the number of
+// addresses it adds for each entry it saves; the result is not guaranteed to be the fewest networks possible within
+// maxWaste.
+func CompressWaste(nets []*net.IPNet, maxWaste Uint128) []*net.IPNet {
+	c := newCompressor(nets)
+	for i := range c.nodes {
+		if c.nodes[i].left >= 0 {
+			heap.Push(&c.heap, i)
+		}
+	}
+	for c.heap.Len() > 0 {
+		i := heap.Pop(&c.heap).(int)
+		if c.nodes[i].waste.Cmp(maxWaste) == 1 {
+			// the merge may become affordable once merges beneath it have paid for some of its waste
+			continue
+		}
+		maxWaste = maxWaste.Minus(c.nodes[i].waste)
+		c.merge(i)
+	}
+	return c.result()
+}
+
+// compressor holds the collapsed networks as the leaves of a binary trie whose inner nodes are the smallest common
+// supernets of neighbouring leaves. Merging an inner node replaces every entry beneath it with the node's network.
+type compressor struct {
+	nodes   []compressNode
+	roots   []int // one per IP version present
+	heap    compressHeap
+	entries int
+}
+
+type compressNode struct {
+	net                 aggNet
+	parent, left, right int  // -1 if absent; leaves have no children
+	merged              bool // the node is an entry or is beneath one
+	// waste is the number of addresses in the node not covered by the entries beneath it, and entries is the number
+	// of entries beneath it; a leaf is a single entry without waste.
+	waste     Uint128
+	entries   int
+	heapIndex int
+}
+
+func newCompressor(nets []*net.IPNet) *compressor {
+	c := new(compressor)
+	c.heap.c = c
+
+	var four, six []aggNet
+	for _, n := range Collapse(nets) {
+		a, _ := newAggNet(n)
+		if a.six {
+			six = append(six, a)
+		} else {
+			four = append(four, a)
+		}
+	}
+	for _, leaves := range [][]aggNet{four, six} {
+		if len(leaves) > 0 {
+			c.roots = append(c.roots, c.build(leaves))
+		}
+	}
+	c.entries = len(four) + len(six)
+	return c
+}
+
+// build adds a trie for the sorted, collapsed leaves and returns its root. The inner nodes, one between each pair of
+// neighbouring leaves, form a Cartesian tree ordered by prefix length, with the shortest at the root.
+func (c *compressor) build(leaves []aggNet) int {
+	base := len(c.nodes)
+	for _, l := range leaves {
+		c.nodes = append(c.nodes, compressNode{net: l, parent: -1, left: -1, right: -1, entries: 1, heapIndex: -1})
+	}
+	inner := len(c.nodes)
+	for i := 0; i+1 < len(leaves); i++ {
+		c.nodes = append(c.nodes, compressNode{
+			net:       commonSupernet(leaves[i], leaves[i+1]),
+			parent:    -1,
+			left:      -1,
+			right:     -1,
+			heapIndex: -1,
+		})
+	}
+
+	var stack []int
+	for j := inner; j < len(c.nodes); j++ {
+		last := -1
+		for len(stack) > 0 && c.nodes[stack[len(stack)-1]].net.prefix > c.nodes[j].net.prefix {
+			last, stack = stack[len(stack)-1], stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			c.nodes[stack[len(stack)-1]].right = j
+		}
+		c.nodes[j].left = last
+		stack = append(stack, j)
+	}
+	if len(stack) == 0 {
+		return base // a single leaf
+	}
+
+	// the leaves fill the empty child slots in order
+	for j := inner; j < len(c.nodes); j++ {
+		if c.nodes[j].left < 0 {
+			c.nodes[j].left = base + j - inner
+		}
+		if c.nodes[j].right < 0 {
+			c.nodes[j].right = base + j - inner + 1
+		}
+		c.nodes[c.nodes[j].left].parent = j
+		c.nodes[c.nodes[j].right].parent = j
+	}
+	c.initWaste(stack[0])
+	return stack[0]
+}
+
+// initWaste sets the waste and entries of the inner nodes beneath i, returning the addresses covered by its leaves.
+func (c *compressor) initWaste(i int) Uint128 {
+	n := &c.nodes[i]
+	if n.left < 0 {
+		return n.net.hostMask().Add(Uint128{0, 1})
+	}
+	covered := c.initWaste(n.left).Add(c.initWaste(n.right))
+	n.entries = c.nodes[n.left].entries + c.nodes[n.right].entries
+	n.waste = n.net.hostMask().Minus(covered).Add(Uint128{0, 1})
+	return covered
+}
+
+// commonSupernet returns the smallest network containing both a and b.
+func commonSupernet(a, b aggNet) aggNet {
+	prefix := a.prefix
+	if b.prefix < prefix {
+		prefix = b.prefix
+	}
+	lz := a.addr.Xor(b.addr).LeadingZeros() - (128 - int(a.bits()))
+	if lz < int(prefix) {
+		prefix = uint8(lz)
+	}
+	n := aggNet{six: a.six, prefix: prefix, addr: a.addr}
+	n.addr = n.addr.AndNot(n.hostMask())
+	return n
+}
+
+// compressPlan records the least waste with which the leaves beneath a node can be covered by at most each number of
+// entries, and how the entries are divided between the node's children to achieve it.
+type compressPlan struct {
+	waste []Uint128 // waste[k-1] is the least waste with at most k entries
+	left  []int32   // left[k-1] is the entries given to the left child, or 0 if the node itself is the entry
+}
+
+// mergeBest merges the nodes which leave at most maxEntries entries with the least waste, giving each root at least
+// one entry.
+func (c *compressor) mergeBest(maxEntries int) {
+	plans := make([]compressPlan, len(c.nodes))
+	for _, r := range c.roots {
+		c.plan(plans, r, maxEntries)
+	}
+	budgets := []int{maxEntries}
+	if len(c.roots) == 2 {
+		budgets = divideEntries(plans[c.roots[0]].waste, plans[c.roots[1]].waste, maxEntries)
+	}
+	c.entries = 0
+	for i, r := range c.roots {
+		c.apply(plans, r, budgets[i])
+	}
+}
+
+// divideEntries returns the entries to give to each of two tries, with the least waste given by a and b for at most
+// each number of entries, so that they have at most maxEntries between them and at least one each.
+func divideEntries(a, b []Uint128, maxEntries int) []int {
+	var best Uint128
+	budgets := []int{1, 1}
+	for i := 1; i <= len(a) && i < maxEntries; i++ {
+		j := maxEntries - i
+		if j > len(b) {
+			j = len(b)
+		}
+		if w := a[i-1].Add(b[j-1]); i == 1 || w.Cmp(best) == -1 {
+			best, budgets[0], budgets[1] = w, i, j
+		}
+	}
+	return budgets
+}
+
+// plan fills in the plans of node i and the nodes beneath it for up to maxEntries entries. The children's waste is
+// dropped once the node's is known, as only the division of entries is needed to apply the plan.
+func (c *compressor) plan(plans []compressPlan, i, maxEntries int) {
+	n := &c.nodes[i]
+	p := &plans[i]
+	if n.left < 0 {
+		p.waste, p.left = []Uint128{{}}, []int32{0}
+		return
+	}
+	c.plan(plans, n.left, maxEntries)
+	c.plan(plans, n.right, maxEntries)
+	l, r := plans[n.left].waste, plans[n.right].waste
+
+	size := len(l) + len(r)
+	if size > maxEntries {
+		size = maxEntries
+	}
+	if size < 1 {
+		size = 1
+	}
+	p.waste, p.left = make([]Uint128, size), make([]int32, size)
+	for k := 1; k <= size; k++ {
+		// merging the node is preferred to dividing the entries between its children for the same waste
+		best, left := n.waste, 0
+		for a := 1; a < k && a <= len(l); a++ {
+			b := k - a
+			if b > len(r) {
+				b = len(r)
+			}
+			if w := l[a-1].Add(r[b-1]); w.Cmp(best) == -1 {
+				best, left = w, a
+			}
+		}
+		p.waste[k-1], p.left[k-1] = best, int32(left)
+	}
+	plans[n.left].waste, plans[n.right].waste = nil, nil
+}
+
+// apply merges the nodes beneath node i chosen by its plan for at most k entries.
+func (c *compressor) apply(plans []compressPlan, i, k int) {
+	n := &c.nodes[i]
+	p := plans[i]
+	if k > len(p.left) {
+		k = len(p.left)
+	} else if k < 1 {
+		k = 1
+	}
+	left := int(p.left[k-1])
+	if left == 0 {
+		n.merged = n.left >= 0
+		c.entries++
+		return
+	}
+	c.apply(plans, n.left, left)
+	c.apply(plans, n.right, k-left)
+}
+
+// merge makes inner node i an entry, removing the entries beneath it and updating the nodes above it.
+func (c *compressor) merge(i int) {
+	n := &c.nodes[i]
+	saved, added := n.entries-1, n.waste
+	c.entries -= saved
+	c.markMerged(n.left)
+	c.markMerged(n.right)
+	n.merged, n.entries, n.waste = true, 1, Uint128{}
+
+	for p := n.parent; p >= 0; p = c.nodes[p].parent {
+		c.nodes[p].entries -= saved
+		c.nodes[p].waste = c.nodes[p].waste.Minus(added)
+		if c.nodes[p].heapIndex >= 0 {
+			heap.Fix(&c.heap, c.nodes[p].heapIndex)
+		} else {
+			heap.Push(&c.heap, p)
+		}
+	}
+}
+
+func (c *compressor) markMerged(i int) {
+	n := &c.nodes[i]
+	if n.merged {
+		return
+	}
+	n.merged = true
+	if n.heapIndex >= 0 {
+		heap.Remove(&c.heap, n.heapIndex)
+	}
+	if n.left >= 0 {
+		c.markMerged(n.left)
+		c.markMerged(n.right)
+	}
+}
+
+func (c *compressor) result() []*net.IPNet {
+	nets := make([]*net.IPNet, 0, c.entries)
+	var walk func(i int)
+	walk = func(i int) {
+		n := c.nodes[i]
+		if n.merged || n.left < 0 {
+			nets = append(nets, n.net.asNet())
+			return
+		}
+		walk(n.left)
+		walk(n.right)
+	}
+	for _, r := range c.roots {
+		walk(r)
+	}
+	return nets
+}
+
+// compressHeap orders inner nodes by the addresses their merge adds for each entry it saves.
+type compressHeap struct {
+	c       *compressor
+	indices []int
+}
+
+func (h compressHeap) Len() int {
+	return len(h.indices)
+}
+
+func (h compressHeap) Less(i, j int) bool {
+	a, b := &h.c.nodes[h.indices[i]], &h.c.nodes[h.indices[j]]
+	// compare a.waste / (a.entries-1) with b.waste / (b.entries-1) without dividing
+	aCost, aOver := a.waste.Mul64Overflow(uint64(b.entries - 1))
+	bCost, bOver := b.waste.Mul64Overflow(uint64(a.entries - 1))
+	switch {
+	case aOver || bOver:
+		return uint128Float(a.waste)/float64(a.entries-1) < uint128Float(b.waste)/float64(b.entries-1)
+	case aCost != bCost:
+		return aCost.Cmp(bCost) == -1
+	}
+	return a.waste.Cmp(b.waste) == -1
+}
+
+func (h compressHeap) Swap(i, j int) {
+	h.indices[i], h.indices[j] = h.indices[j], h.indices[i]
+	h.c.nodes[h.indices[i]].heapIndex = i
+	h.c.nodes[h.indices[j]].heapIndex = j
+}
+
+func (h *compressHeap) Push(x interface{}) {
+	i := x.(int)
+	h.c.nodes[i].heapIndex = len(h.indices)
+	h.indices = append(h.indices, i)
+}
+
+func (h *compressHeap) Pop() interface{} {
+	i := h.indices[len(h.indices)-1]
+	h.indices = h.indices[:len(h.indices)-1]
+	h.c.nodes[i].heapIndex = -1
+	return i
+}
+
+func uint128Float(u Uint128) float64 {
+	return float64(u.H)*(1<<64) + float64(u.L)
+}
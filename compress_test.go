@@ -0,0 +1,159 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"math/rand"
+	"net"
+	"testing"
+)
+
+func ExampleCompress() {
+	nets := cidrs([]string{"10.0.0.0/24", "10.0.2.0/24", "10.0.8.0/24", "10.0.9.0/24"})
+	fmt.Println(ipx.Compress(nets, 2))
+	fmt.Println(ipx.Compress(nets, 1))
+	// Output:
+	// [10.0.0.0/22 10.0.8.0/23]
+	// [10.0.0.0/20]
+}
+
+func ExampleCompressWaste() {
+	nets := cidrs([]string{"10.0.0.0/24", "10.0.2.0/24", "10.0.8.0/24"})
+	fmt.Println(ipx.CompressWaste(nets, ipx.Uint128{L: 511}))
+	fmt.Println(ipx.CompressWaste(nets, ipx.Uint128{L: 512}))
+	// Output:
+	// [10.0.0.0/24 10.0.2.0/24 10.0.8.0/24]
+	// [10.0.0.0/22 10.0.8.0/24]
+}
+
+func TestCompress(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		in       []string
+		max      int
+		expected string
+	}{
+		{"empty", nil, 1, "[]"},
+		{"enough entries", []string{"10.0.0.0/25", "10.0.0.128/25", "10.0.2.0/24"}, 2, "[10.0.0.0/24 10.0.2.0/24]"},
+		{"cheapest per entry", []string{"10.0.0.0/32", "10.0.0.2/32", "10.0.0.4/32", "10.0.0.6/32", "10.0.1.0/24"}, 2, "[10.0.0.0/29 10.0.1.0/24]"},
+		{"no more merges than needed", []string{"10.0.0.0/32", "10.0.0.2/32", "10.0.0.4/32", "10.0.0.6/32", "10.0.0.128/32"}, 4, "[10.0.0.0/30 10.0.0.4/32 10.0.0.6/32 10.0.0.128/32]"},
+		{"versions kept apart", []string{"10.0.0.0/8", "11.0.0.0/8", "2001:db8::/32", "2001:db9::/32"}, 1, "[10.0.0.0/7 2001:db8::/31]"},
+		{"whole space", []string{"0.0.0.0/32", "255.255.255.255/32", "::/128", "ffff::/16"}, 2, "[0.0.0.0/0 ::/0]"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := fmt.Sprint(ipx.Compress(cidrs(c.in), c.max)); got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestCompressCovers(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		nets := randomNets(r, 1+r.Intn(200))
+		collapsed := ipx.Collapse(nets)
+		max := 2 + r.Intn(len(collapsed)+1)
+
+		got := ipx.Compress(nets, max)
+		if len(got) > max {
+			t.Fatalf("expected at most %v networks but got %v", max, len(got))
+		}
+		if len(ipx.Collapse(got)) != len(got) {
+			t.Fatalf("expected disjoint networks but got %v", got)
+		}
+		if len(collapsed) <= max && fmt.Sprint(got) != fmt.Sprint(collapsed) {
+			t.Fatalf("expected %v unchanged but got %v", collapsed, got)
+		}
+		if remainder := ipx.ExcludeAll(nets, got); len(remainder) != 0 {
+			t.Fatalf("%v not covered by %v", remainder, got)
+		}
+
+		budget := ipx.Uint128{L: uint64(r.Intn(1 << 12))}
+		wasteful := ipx.CompressWaste(nets, budget)
+		if remainder := ipx.ExcludeAll(nets, wasteful); len(remainder) != 0 {
+			t.Fatalf("%v not covered by %v", remainder, wasteful)
+		}
+		if waste := size(ipx.ExcludeAll(wasteful, nets)); waste.Cmp(budget) == 1 {
+			t.Fatalf("expected waste within %v but got %v", budget, waste)
+		}
+	}
+}
+
+func TestCompressOptimal(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	for i := 0; i < 300; i++ {
+		nets := ipx.Collapse(randomNets(r, 1+r.Intn(16)))
+		if len(nets) > 10 {
+			nets = ipx.Collapse(nets[:10])
+		}
+		versions := 0
+		if nets[0].IP.To4() != nil {
+			versions++
+		}
+		if nets[len(nets)-1].IP.To4() == nil {
+			versions++
+		}
+		max := versions + r.Intn(len(nets)-versions+1)
+
+		got := ipx.Compress(nets, max)
+		if len(got) > max {
+			t.Fatalf("expected at most %v networks but got %v", max, got)
+		}
+		if waste, expected := size(ipx.ExcludeAll(got, nets)), bruteCompress(nets, max); waste != expected {
+			t.Fatalf("for %v in %v networks expected waste %v but got %v from %v", nets, max, expected, waste, got)
+		}
+	}
+}
+
+// bruteCompress returns the least waste of the covers of the collapsed networks by at most max networks, trying every
+// division of the networks into runs of neighbours of the same version, each covered by its smallest supernet.
+func bruteCompress(nets []*net.IPNet, max int) ipx.Uint128 {
+	var best ipx.Uint128
+	found := false
+	for splits := 0; splits < 1<<uint(len(nets)-1); splits++ {
+		var cover []*net.IPNet
+		start, ok := 0, true
+		for i := 1; ok && i <= len(nets); i++ {
+			if i < len(nets) && splits&(1<<uint(i-1)) == 0 {
+				ok = (nets[i].IP.To4() == nil) == (nets[start].IP.To4() == nil)
+				continue
+			}
+			cover = append(cover, supernet(nets[start], nets[i-1]))
+			start = i
+		}
+		if cover = ipx.Collapse(cover); !ok || len(cover) > max {
+			continue
+		}
+		if waste := size(ipx.ExcludeAll(cover, nets)); !found || waste.Cmp(best) == -1 {
+			best, found = waste, true
+		}
+	}
+	return best
+}
+
+// supernet returns the smallest network containing a and the later network b.
+func supernet(a, b *net.IPNet) *net.IPNet {
+	ones, bits := a.Mask.Size()
+	for {
+		m := net.CIDRMask(ones, bits)
+		if n := (&net.IPNet{IP: a.IP.Mask(m), Mask: m}); n.Contains(b.IP) && n.Contains(last(b)) {
+			return n
+		}
+		ones--
+	}
+}
+
+func last(n *net.IPNet) net.IP {
+	_, end := ipx.NetToRange(n)
+	return end
+}
+
+func size(nets []*net.IPNet) ipx.Uint128 {
+	var total ipx.Uint128
+	for _, n := range nets {
+		ones, bits := n.Mask.Size()
+		total = total.Add(ipx.Uint128{L: 1}.Lsh(uint(bits - ones)))
+	}
+	return total
+}
@@ -0,0 +1,61 @@
+package ipx
+
+import (
+	"errors"
+	"math/big"
+	"net"
+)
+
+// IPToUint32 returns the integer value of an IPv4 address, which may be in its 4 or 16 byte form. It returns an error
+// if ip is not an IPv4 address.
+func IPToUint32(ip net.IP) (uint32, error) {
+	four := ip.To4()
+	if four == nil {
+		return 0, errors.New("not an IPv4 address")
+	}
+	return to32(four), nil
+}
+
+// Uint32ToIP returns the IPv4 address for the integer value, in the 16 byte form used by net.IPv4.
+func Uint32ToIP(n uint32) net.IP {
+	ip := make(net.IP, net.IPv6len)
+	copy(ip, v4InV6Prefix)
+	from32(n, ip)
+	return ip
+}
+
+// IPToUint128 returns the integer value of an IP address. IPv4 addresses -- whether in their 4 byte form, 16 byte form
+// or written as v4-mapped IPv6 addresses -- return their 32 bit value, so the result is only meaningful alongside the
+// IP version; use Uint128ToIP to convert back. It returns an error if ip is neither 4 nor 16 bytes long.
+func IPToUint128(ip net.IP) (Uint128, error) {
+	if four := ip.To4(); four != nil {
+		return Uint128{0, uint64(to32(four))}, nil
+	}
+	if len(ip) != net.IPv6len {
+		return Uint128{}, errors.New("invalid IP length")
+	}
+	return To128(ip), nil
+}
+
+// Uint128ToIP returns the IP address for the integer value. If v6 is false, the value must fit in 32 bits and an
+// IPv4 address is returned; otherwise nil is returned.
+func Uint128ToIP(u Uint128, v6 bool) net.IP {
+	if !v6 {
+		if u.H != 0 || u.L > maxUint32 {
+			return nil
+		}
+		return Uint32ToIP(uint32(u.L))
+	}
+	ip := make(net.IP, net.IPv6len)
+	From128(u, ip)
+	return ip
+}
+
+// IPToBig returns the integer value of an IP address as a big.Int, following the same rules as IPToUint128.
+func IPToBig(ip net.IP) (*big.Int, error) {
+	u, err := IPToUint128(ip)
+	if err != nil {
+		return nil, err
+	}
+	return u.Big(), nil
+}
@@ -0,0 +1,103 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleIPToUint32() {
+	n, _ := ipx.IPToUint32(net.ParseIP("192.0.2.1"))
+	fmt.Println(n)
+	fmt.Println(ipx.Uint32ToIP(n))
+	// Output:
+	// 3221225985
+	// 192.0.2.1
+}
+
+func ExampleIPToUint128() {
+	u, _ := ipx.IPToUint128(net.ParseIP("2001:db8::1"))
+	fmt.Println(u)
+	fmt.Println(ipx.Uint128ToIP(u, true))
+	// Output:
+	// 42540766411282592856903984951653826561
+	// 2001:db8::1
+}
+
+func TestIPToUint32(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		ip       net.IP
+		expected uint32
+		err      bool
+	}{
+		{"4 byte", net.IP{10, 0, 0, 1}, 0x0a000001, false},
+		{"16 byte", net.ParseIP("10.0.0.1"), 0x0a000001, false},
+		{"max", net.ParseIP("255.255.255.255"), 0xffffffff, false},
+		{"ipv6", net.ParseIP("2001:db8::1"), 0, true},
+		{"nil", nil, 0, true},
+		{"short", net.IP{1, 2}, 0, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ipx.IPToUint32(c.ip)
+			if (err != nil) != c.err {
+				t.Fatalf("expected error %v but got %v", c.err, err)
+			}
+			if got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestIPToUint128(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		ip       net.IP
+		expected ipx.Uint128
+		v6       bool
+		err      bool
+	}{
+		{"4 byte", net.IP{10, 0, 0, 1}, ipx.Uint128{L: 0x0a000001}, false, false},
+		{"16 byte", net.ParseIP("10.0.0.1"), ipx.Uint128{L: 0x0a000001}, false, false},
+		{"v4-mapped", net.ParseIP("::ffff:10.0.0.1"), ipx.Uint128{L: 0x0a000001}, false, false},
+		{"ipv6", net.ParseIP("2001:db8::1"), ipx.Uint128{H: 0x20010db800000000, L: 1}, true, false},
+		{"max", net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"), ipx.Uint128{H: 1<<64 - 1, L: 1<<64 - 1}, true, false},
+		{"nil", nil, ipx.Uint128{}, false, true},
+		{"short", net.IP{1, 2}, ipx.Uint128{}, false, true},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ipx.IPToUint128(c.ip)
+			if (err != nil) != c.err {
+				t.Fatalf("expected error %v but got %v", c.err, err)
+			}
+			if got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+			if c.err {
+				return
+			}
+			if back := ipx.Uint128ToIP(got, c.v6); !back.Equal(c.ip) {
+				t.Fatalf("expected round trip to %v but got %v", c.ip, back)
+			}
+
+			b, err := ipx.IPToBig(c.ip)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if b.Cmp(got.Big()) != 0 {
+				t.Fatalf("expected big %v but got %v", got, b)
+			}
+		})
+	}
+}
+
+func TestUint128ToIP(t *testing.T) {
+	if ip := ipx.Uint128ToIP(ipx.Uint128{L: 1 << 32}, false); ip != nil {
+		t.Fatalf("expected nil for out of range IPv4 value but got %v", ip)
+	}
+	if ip := ipx.Uint128ToIP(ipx.Uint128{L: 1}, true); ip.String() != "::1" {
+		t.Fatalf("expected ::1 but got %v", ip)
+	}
+}
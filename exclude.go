@@ -55,3 +55,41 @@ func exclude6(a, b ip6Net) []*net.IPNet {
 	}
 	return subs
 }
+
+// ExcludeAll returns the minimal list of networks covering the addresses in from which aren't in remove, IPv4 networks
+// first and each version in ascending order. The networks may overlap in any way and be of either IP version. Host
+// bits set in any of the networks are ignored, as are networks with invalid masks.
+func ExcludeAll(from, remove []*net.IPNet) []*net.IPNet {
+	f, r := NewNetSet(from), NewNetSet(remove)
+	return (&NetSet{four: subtractSpans(f.four, r.four), six: subtractSpans(f.six, r.six)}).Nets()
+}
+
+// subtractSpans returns the parts of the spans in a not in any span of b. Both must be sorted and disjoint.
+func subtractSpans(a, b []span) []span {
+	var result []span
+	for _, s := range a {
+		// skip removals entirely before s; they're also before every later span of a
+		for len(b) > 0 && b[0].last.Cmp(s.first) == -1 {
+			b = b[1:]
+		}
+		remaining := true
+		for _, rm := range b {
+			if rm.first.Cmp(s.last) == 1 {
+				break
+			}
+			if rm.first.Cmp(s.first) == 1 {
+				result = append(result, span{s.first, rm.first.Minus(Uint128{0, 1})})
+			}
+			next, overflow := rm.last.AddOverflow(Uint128{0, 1})
+			if overflow || next.Cmp(s.last) == 1 {
+				remaining = false
+				break
+			}
+			s.first = next
+		}
+		if remaining {
+			result = append(result, s)
+		}
+	}
+	return result
+}
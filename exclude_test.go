@@ -3,6 +3,8 @@ package ipx_test
 import (
 	"fmt"
 	"github.com/ns1/ipx"
+	"math/rand"
+	"net"
 	"testing"
 )
 
@@ -105,3 +107,62 @@ func BenchmarkExclude(b *testing.B) {
 		})
 	}
 }
+
+func ExampleExcludeAll() {
+	fmt.Println(ipx.ExcludeAll(
+		[]*net.IPNet{cidr("10.0.0.0/24"), cidr("2001:db8::/32")},
+		[]*net.IPNet{cidr("10.0.0.64/26"), cidr("10.0.0.0/30"), cidr("2001:db8:8000::/33")},
+	))
+	// Output:
+	// [10.0.0.4/30 10.0.0.8/29 10.0.0.16/28 10.0.0.32/27 10.0.0.128/25 2001:db8::/33]
+}
+
+func TestExcludeAll(t *testing.T) {
+	for _, c := range []struct {
+		name         string
+		from, remove []string
+		expected     string
+	}{
+		{"nothing removed", []string{"10.0.0.0/24"}, nil, "[10.0.0.0/24]"},
+		{"nothing left", []string{"10.0.0.0/24"}, []string{"10.0.0.0/8"}, "[]"},
+		{"partial overlap", []string{"10.0.0.0/25", "10.0.0.128/26"}, []string{"10.0.0.64/26", "10.0.0.128/27"}, "[10.0.0.0/26 10.0.0.160/27]"},
+		{"duplicates", []string{"10.0.0.0/24", "10.0.0.0/24"}, []string{"10.0.0.0/25", "10.0.0.0/25"}, "[10.0.0.128/25]"},
+		{"mixed versions", []string{"10.0.0.0/31", "2001:db8::/127"}, []string{"2001:db8::/128", "10.0.0.1/32"}, "[10.0.0.0/32 2001:db8::1/128]"},
+		{"disjoint", []string{"10.0.0.0/24"}, []string{"10.0.1.0/24", "9.0.0.0/8"}, "[10.0.0.0/24]"},
+		{"top of space", []string{"0.0.0.0/0"}, []string{"255.255.255.255/32", "0.0.0.0/32"}, "[0.0.0.1/32 0.0.0.2/31 0.0.0.4/30 0.0.0.8/29 0.0.0.16/28 0.0.0.32/27 0.0.0.64/26 0.0.0.128/25 0.0.1.0/24 0.0.2.0/23 0.0.4.0/22 0.0.8.0/21 0.0.16.0/20 0.0.32.0/19 0.0.64.0/18 0.0.128.0/17 0.1.0.0/16 0.2.0.0/15 0.4.0.0/14 0.8.0.0/13 0.16.0.0/12 0.32.0.0/11 0.64.0.0/10 0.128.0.0/9 1.0.0.0/8 2.0.0.0/7 4.0.0.0/6 8.0.0.0/5 16.0.0.0/4 32.0.0.0/3 64.0.0.0/2 128.0.0.0/2 192.0.0.0/3 224.0.0.0/4 240.0.0.0/5 248.0.0.0/6 252.0.0.0/7 254.0.0.0/8 255.0.0.0/9 255.128.0.0/10 255.192.0.0/11 255.224.0.0/12 255.240.0.0/13 255.248.0.0/14 255.252.0.0/15 255.254.0.0/16 255.255.0.0/17 255.255.128.0/18 255.255.192.0/19 255.255.224.0/20 255.255.240.0/21 255.255.248.0/22 255.255.252.0/23 255.255.254.0/24 255.255.255.0/25 255.255.255.128/26 255.255.255.192/27 255.255.255.224/28 255.255.255.240/29 255.255.255.248/30 255.255.255.252/31 255.255.255.254/32]"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := fmt.Sprint(ipx.ExcludeAll(cidrs(c.from), cidrs(c.remove))); got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestExcludeAllMatchesExclude(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		from, remove := randomNets(r, 1+r.Intn(20)), randomNets(r, r.Intn(20))
+
+		// repeatedly exclude each removal from everything containing it, dropping anything inside a removal
+		expected := ipx.Collapse(from)
+		for _, rm := range remove {
+			var next []*net.IPNet
+			for _, n := range expected {
+				switch {
+				case ipx.IsSubnet(rm, n):
+					// removed entirely
+				case ipx.IsSubnet(n, rm):
+					next = append(next, ipx.Exclude(n, rm)...)
+				default:
+					next = append(next, n)
+				}
+			}
+			expected = ipx.Collapse(next)
+		}
+
+		if got := ipx.ExcludeAll(from, remove); fmt.Sprint(got) != fmt.Sprint(expected) {
+			t.Fatalf("%v minus %v: expected %v but got %v", from, remove, expected, got)
+		}
+	}
+}
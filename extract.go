@@ -0,0 +1,272 @@
+package ipx
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+)
+
+// Match is an address, network or range found in text by an AddrScanner.
+type Match struct {
+	// Offset is the byte offset of the match in the input; Line and Column give its 1-based line and byte column.
+	Offset       int64
+	Line, Column int
+	// Text is the match as written, including any defanging.
+	Text string
+	// IP is the address, the address before the prefix length of a network, or the first address of a range.
+	IP net.IP
+	// Net is set when the match is written in CIDR notation. Host bits are cleared.
+	Net *net.IPNet
+	// Last is set to the last address when the match is a range, e.g. 192.0.2.1-192.0.2.9.
+	Last net.IP
+}
+
+// Nets returns the networks covering exactly the matched addresses.
+func (m Match) Nets() []*net.IPNet {
+	switch {
+	case m.Net != nil:
+		return []*net.IPNet{m.Net}
+	case m.Last != nil:
+		return SummarizeRange(m.IP, m.Last)
+	}
+	ip, bits := ipAndBits(m.IP)
+	return []*net.IPNet{{IP: ip, Mask: net.CIDRMask(bits, bits)}}
+}
+
+// AddrScanner finds IPv4 and IPv6 addresses, networks in CIDR notation and ranges in free-form text such as logs,
+// emails and HTML. It understands addresses in brackets or followed by ports, e.g. [2001:db8::1]:443 or 1.2.3.4:80,
+// defanged addresses, e.g. 1.2.3[.]4, addresses following keys, e.g. src_ip:10.0.0.1, and addresses followed by
+// punctuation ending a sentence. Dotted numbers which are part of something longer, such as version strings like
+// 1.2.3.4.5 or v1.2.3.4, are not matched.
+type AddrScanner struct {
+	r     *bufio.Reader
+	line  string
+	num   int   // of the current line
+	start int64 // offset of the current line
+	pos   int   // within the current line
+	match Match
+	err   error
+}
+
+// NewAddrScanner returns a scanner reading from r.
+func NewAddrScanner(r io.Reader) *AddrScanner {
+	return &AddrScanner{r: bufio.NewReader(r)}
+}
+
+// Reset discards the scanner's state and switches it to reading from r, reusing its buffer.
+func (s *AddrScanner) Reset(r io.Reader) {
+	s.r.Reset(r)
+	*s = AddrScanner{r: s.r}
+}
+
+// Scan advances to the next match, returning false when the input is exhausted or an error occurs.
+func (s *AddrScanner) Scan() bool {
+	for {
+		if m, ok := s.scanLine(); ok {
+			s.match = m
+			return true
+		}
+		if s.err != nil {
+			return false
+		}
+
+		s.start += int64(len(s.line))
+		line, err := s.r.ReadString('\n')
+		if err != nil && err != io.EOF {
+			s.err = err
+			return false
+		}
+		if line == "" {
+			s.err = io.EOF
+			return false
+		}
+		s.line, s.pos = line, 0
+		s.num++
+	}
+}
+
+// Match returns the most recent match.
+func (s *AddrScanner) Match() Match {
+	return s.match
+}
+
+// Err returns the first error encountered reading the input, other than io.EOF.
+func (s *AddrScanner) Err() error {
+	if s.err == io.EOF {
+		return nil
+	}
+	return s.err
+}
+
+func (s *AddrScanner) scanLine() (Match, bool) {
+	for s.pos < len(s.line) {
+		i := s.pos
+		if !isAddrByte(s.line[i]) || (i > 0 && isWordByte(s.line[i-1]) && !followsKey(s.line, i-1)) {
+			s.pos++
+			continue
+		}
+
+		ip, end, ok := readAddr(s.line, i)
+		if !ok {
+			// skip the whole candidate so that no match is found in the middle of it
+			s.pos = end
+			continue
+		}
+		m := Match{IP: ip}
+		if ipNet, netEnd, ok := readPrefix(s.line, end, ip); ok {
+			m.Net, end = ipNet, netEnd
+		} else if last, rangeEnd, ok := readRangeEnd(s.line, end, ip); ok {
+			m.Last, end = last, rangeEnd
+		}
+
+		m.Offset, m.Line, m.Column = s.start+int64(i), s.num, i+1
+		m.Text = s.line[i:end]
+		s.pos = end
+		return m, true
+	}
+	return Match{}, false
+}
+
+// readAddr reads the address starting at i, returning it and the index following it. When no address is found, the
+// index following the candidate is returned.
+func readAddr(line string, i int) (net.IP, int, bool) {
+	var (
+		norm []byte
+		ends []int // index in line following each byte of norm
+	)
+	for j := i; j < len(line); {
+		if c := line[j]; isAddrByte(c) {
+			norm, ends = append(norm, c), append(ends, j+1)
+			j++
+			continue
+		}
+		if c, n := defanged(line[j:]); n > 0 && len(norm) > 0 {
+			norm, ends = append(norm, c), append(ends, j+n)
+			j += n
+			continue
+		}
+		break
+	}
+	if len(norm) == 0 {
+		return nil, i + 1, false
+	}
+	candidateEnd := ends[len(ends)-1]
+
+	ip, n := parseCandidate(string(norm))
+	if ip == nil {
+		return nil, candidateEnd, false
+	}
+	end := ends[n-1]
+	if end < len(line) && isWordByte(line[end]) && line[end] != '.' && line[end] != ':' {
+		return nil, candidateEnd, false
+	}
+	return ip, end, true
+}
+
+// parseCandidate returns the address at the start of s and the number of bytes it occupies. The address may be
+// followed by punctuation or, for IPv4, a port.
+func parseCandidate(s string) (net.IP, int) {
+	if strings.Trim(s, ".:") == "" {
+		return nil, 0
+	}
+	for n := len(s); n > 0; n-- {
+		if ip := net.ParseIP(s[:n]); ip != nil {
+			return ip, n
+		}
+		if c := s[n-1]; c != '.' && c != ':' {
+			break
+		}
+	}
+	// an IPv4 address followed by a port
+	if dot, colon := strings.IndexByte(s, '.'), strings.IndexByte(s, ':'); dot >= 0 && colon > dot {
+		if ip := net.ParseIP(s[:colon]); ip != nil {
+			return ip, colon
+		}
+	}
+	return nil, 0
+}
+
+// readPrefix reads a prefix length following ip at i, returning the network and the index following it.
+func readPrefix(line string, i int, ip net.IP) (*net.IPNet, int, bool) {
+	if i >= len(line) || line[i] != '/' {
+		return nil, 0, false
+	}
+	j := i + 1
+	ones := 0
+	for ; j < len(line) && j-i <= 3 && '0' <= line[j] && line[j] <= '9'; j++ {
+		ones = ones*10 + int(line[j]-'0')
+	}
+	ip, bits := ipAndBits(ip)
+	if j == i+1 || ones > bits || (j < len(line) && isWordByte(line[j]) && line[j] != '.') {
+		return nil, 0, false
+	}
+	mask := net.CIDRMask(ones, bits)
+	return &net.IPNet{IP: ip.Mask(mask), Mask: mask}, j, true
+}
+
+// readRangeEnd reads the last address of a range starting with first, following a hyphen at i.
+func readRangeEnd(line string, i int, first net.IP) (net.IP, int, bool) {
+	j := i
+	for j < len(line) && line[j] == ' ' {
+		j++
+	}
+	if j >= len(line) || line[j] != '-' {
+		return nil, 0, false
+	}
+	for j++; j < len(line) && line[j] == ' '; j++ {
+	}
+	if j >= len(line) || !isAddrByte(line[j]) {
+		return nil, 0, false
+	}
+	last, end, ok := readAddr(line, j)
+	if !ok || (first.To4() == nil) != (last.To4() == nil) || CmpIP(first, last) == 1 {
+		return nil, 0, false
+	}
+	return last, end, true
+}
+
+// defanged returns the separator written in defanged form at the start of s, e.g. [.], and its length.
+func defanged(s string) (byte, int) {
+	for _, d := range []string{"[.]", "(.)", "{.}", "[:]"} {
+		if strings.HasPrefix(s, d) {
+			return d[1], len(d)
+		}
+	}
+	return 0, 0
+}
+
+// followsKey returns whether the separator at i ends a key, as in host:1.2.3.4, so that an address may start after it.
+// Keys have a byte no address has, telling them from the groups of an IPv6 address, and a dot after a digit is
+// taken to be part of a version number.
+func followsKey(line string, i int) bool {
+	if c := line[i]; i == 0 || c != ':' && c != '.' || c == '.' && '0' <= line[i-1] && line[i-1] <= '9' {
+		return false
+	}
+	key := false
+	for j := i - 1; j >= 0 && isWordByte(line[j]) && line[j] != ':' && line[j] != '.'; j-- {
+		key = key || !isAddrByte(line[j])
+	}
+	return key
+}
+
+func isAddrByte(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= lowerASCII(c) && lowerASCII(c) <= 'f' || c == '.' || c == ':'
+}
+
+func isWordByte(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= lowerASCII(c) && lowerASCII(c) <= 'z' || c == '_' || c == '.' || c == ':'
+}
+
+// ExtractNets returns the collapsed networks covering every address, network and range found in r by an AddrScanner.
+func ExtractNets(r io.Reader) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	s := NewAddrScanner(r)
+	for s.Scan() {
+		nets = append(nets, s.Match().Nets()...)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return Collapse(nets), nil
+}
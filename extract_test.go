@@ -0,0 +1,88 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"strings"
+	"testing"
+)
+
+func ExampleAddrScanner() {
+	s := ipx.NewAddrScanner(strings.NewReader(
+		"blocked 198.51.100.7:443 and [2001:db8::1]:443\n" +
+			"indicators: 203.0.113[.]9, 192.0.2.0/24 (see 10.0.0.1-10.0.0.9).\n",
+	))
+	for s.Scan() {
+		m := s.Match()
+		fmt.Printf("%d:%d %q %v\n", m.Line, m.Column, m.Text, m.Nets())
+	}
+	// Output:
+	// 1:9 "198.51.100.7" [198.51.100.7/32]
+	// 1:31 "2001:db8::1" [2001:db8::1/128]
+	// 2:13 "203.0.113[.]9" [203.0.113.9/32]
+	// 2:28 "192.0.2.0/24" [192.0.2.0/24]
+	// 2:46 "10.0.0.1-10.0.0.9" [10.0.0.1/32 10.0.0.2/31 10.0.0.4/30 10.0.0.8/31]
+}
+
+func ExampleExtractNets() {
+	nets, _ := ipx.ExtractNets(strings.NewReader("10.0.0.1, 10.0.0.0, 10.0.0.2; 10.0.0.3. 10.0.0.0/31"))
+	fmt.Println(nets)
+	// Output:
+	// [10.0.0.0/30]
+}
+
+func TestAddrScanner(t *testing.T) {
+	for _, c := range []struct {
+		in       string
+		expected []string
+	}{
+		{"client=1.2.3.4:80 ok", []string{"1.2.3.4"}},
+		{"see 1.2.3.4.", []string{"1.2.3.4"}},
+		{"<td>10.1.1.1</td>", []string{"10.1.1.1"}},
+		{"\"192.0.2.1\",'192.0.2.2'", []string{"192.0.2.1", "192.0.2.2"}},
+		{"1[.]2[.]3[.]4 and 5.6.7(.)8", []string{"1[.]2[.]3[.]4", "5.6.7(.)8"}},
+		{"2001:db8[:]:1", []string{"2001:db8[:]:1"}},
+		{"at ::1, fe80::1 and ::ffff:192.0.2.1", []string{"::1", "fe80::1", "::ffff:192.0.2.1"}},
+		{"route 2001:db8::/32.", []string{"2001:db8::/32"}},
+		{"from 10.0.0.1 - 10.0.0.5", []string{"10.0.0.1 - 10.0.0.5"}},
+		{"backwards 10.0.0.5-10.0.0.1", []string{"10.0.0.5", "10.0.0.1"}},
+		{"mixed 10.0.0.1-::1", []string{"10.0.0.1", "::1"}},
+		{"bad prefix 10.0.0.0/33", []string{"10.0.0.0"}},
+		{"version 1.2.3.4.5 v1.2.3.4 1.2.3.4a", nil},
+		{"time 12:34:56 add std::vector :: 999.1.1.1", nil},
+		{"deadbeef cafe:babe", nil},
+		{"host:1.2.3.4 IP:10.0.0.1 src_ip:10.0.0.2", []string{"1.2.3.4", "10.0.0.1", "10.0.0.2"}},
+		{"dst:2001:db8::1 x", []string{"2001:db8::1"}},
+		{"ip4:192.0.2.0/24 ip6:2001:db8::/32 -all", []string{"192.0.2.0/24", "2001:db8::/32"}},
+		{"server.10.0.0.1 v10.0.0.0.1 cafe:1.2.3.4", []string{"10.0.0.1"}},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			var got []string
+			s := ipx.NewAddrScanner(strings.NewReader(c.in))
+			for s.Scan() {
+				got = append(got, s.Match().Text)
+			}
+			if s.Err() != nil {
+				t.Fatalf("unexpected error: %v", s.Err())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(c.expected) {
+				t.Fatalf("expected %q but got %q", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestAddrScannerOffsets(t *testing.T) {
+	in := "a 1.1.1.1\n\nbb 2.2.2.2 3.3.3.3"
+	s := ipx.NewAddrScanner(strings.NewReader(in))
+	for s.Scan() {
+		m := s.Match()
+		if in[m.Offset:m.Offset+int64(len(m.Text))] != m.Text {
+			t.Fatalf("offset %v does not match %q", m.Offset, m.Text)
+		}
+		lines := strings.Split(in, "\n")
+		if line := lines[m.Line-1]; !strings.HasPrefix(line[m.Column-1:], m.Text) {
+			t.Fatalf("line %v column %v does not match %q", m.Line, m.Column, m.Text)
+		}
+	}
+}
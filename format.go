@@ -0,0 +1,175 @@
+package ipx
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// IPFormat selects the notation FormatIP writes an address in.
+type IPFormat uint8
+
+const (
+	// FormatCanonical writes IPv4 addresses in dotted decimal and IPv6 addresses per RFC 5952: hex groups without
+	// leading zeros, with the longest run of two or more zero groups replaced by "::".
+	FormatCanonical IPFormat = iota
+	// FormatExploded writes IPv6 addresses as all eight groups of four hex digits, e.g. 2001:0db8:0000:...:0001.
+	// IPv4 addresses are written as with FormatCanonical.
+	FormatExploded
+	// FormatMixed writes the last 32 bits of IPv6 addresses in dotted decimal, e.g. 64:ff9b::192.0.2.1. IPv4
+	// addresses are written as v4-mapped IPv6 addresses, e.g. ::ffff:192.0.2.1.
+	FormatMixed
+	// FormatDecimal writes the integer value of the address in decimal.
+	FormatDecimal
+	// FormatHex writes the integer value of the address as 0x followed by 8 or 32 hex digits.
+	FormatHex
+	// FormatBinary writes the integer value of the address as 0b followed by 32 or 128 binary digits.
+	FormatBinary
+)
+
+// FormatOptions controls the output of FormatIP and FormatNet.
+type FormatOptions struct {
+	Format IPFormat
+	// Uppercase writes hex digits in upper case.
+	Uppercase bool
+}
+
+// FormatIP returns the text of ip in the notation selected by opts. The integer notations may be read back with
+// ParseUint128 and base 0. Addresses which are neither 4 nor 16 bytes long are written as by net.IP.String.
+func FormatIP(ip net.IP, opts FormatOptions) string {
+	var b strings.Builder
+	if !appendIP(&b, ip, opts) {
+		return ip.String()
+	}
+	return b.String()
+}
+
+// FormatNet returns the text of ipNet as its address, written as by FormatIP, followed by a slash and the prefix
+// length. When FormatMixed writes an IPv4 network as a v4-mapped IPv6 network, the prefix length is adjusted to match,
+// as it is when an IPv4 address with a 16 byte mask, e.g. from net.ParseCIDR("::ffff:10.0.0.0/104"), is written as
+// IPv4. Such networks with prefixes too short for IPv4 are written in FormatMixed. Networks with non-contiguous masks
+// are written as by net.IPNet.String.
+func FormatNet(ipNet *net.IPNet, opts FormatOptions) string {
+	ones, bits := ipNet.Mask.Size()
+	if bits == 0 {
+		return ipNet.String()
+	}
+	if bits == 8*net.IPv6len && ipNet.IP.To4() != nil {
+		if ones < 96 {
+			opts.Format = FormatMixed
+		} else {
+			ones, bits = ones-96, 8*net.IPv4len
+		}
+	}
+
+	var b strings.Builder
+	if !appendIP(&b, ipNet.IP, opts) {
+		return ipNet.String()
+	}
+	if opts.Format == FormatMixed && bits == 8*net.IPv4len {
+		ones += 8 * (net.IPv6len - net.IPv4len)
+	}
+	b.WriteByte('/')
+	b.WriteString(strconv.Itoa(ones))
+	return b.String()
+}
+
+func appendIP(b *strings.Builder, ip net.IP, opts FormatOptions) bool {
+	ip, bits := ipAndBits(ip)
+	if ip == nil {
+		return false
+	}
+	four := bits == 8*net.IPv4len
+
+	switch opts.Format {
+	case FormatDecimal, FormatHex, FormatBinary:
+		u, _ := IPToUint128(ip)
+		base, prefix, width := 10, "", 0
+		switch opts.Format {
+		case FormatHex:
+			base, prefix, width = 16, "0x", bits/4
+		case FormatBinary:
+			base, prefix, width = 2, "0b", bits
+		}
+		digits := u.Big().Text(base)
+		if opts.Uppercase {
+			digits = strings.ToUpper(digits)
+		}
+		b.WriteString(prefix)
+		for i := len(digits); i < width; i++ {
+			b.WriteByte('0')
+		}
+		b.WriteString(digits)
+	case FormatMixed:
+		groups := v4InV6Prefix
+		if !four {
+			groups = ip[:12]
+		}
+		appendGroups(b, groups, opts, true)
+		appendDottedDecimal(b, ip[len(ip)-4:])
+	default:
+		if four {
+			appendDottedDecimal(b, ip)
+			break
+		}
+		appendGroups(b, ip, opts, false)
+	}
+	return true
+}
+
+func appendDottedDecimal(b *strings.Builder, ip net.IP) {
+	for i, octet := range ip {
+		if i > 0 {
+			b.WriteByte('.')
+		}
+		b.WriteString(strconv.Itoa(int(octet)))
+	}
+}
+
+// appendGroups writes the 16 bit groups of ip. If trailing is set, more of the address follows, so the groups are
+// always terminated by a colon.
+func appendGroups(b *strings.Builder, ip []byte, opts FormatOptions, trailing bool) {
+	groups := len(ip) / 2
+
+	// find the longest run of at least two zero groups, preferring the first
+	zStart, zEnd := -1, -1
+	if opts.Format != FormatExploded {
+		for i := 0; i < groups; i++ {
+			j := i
+			for j < groups && ip[2*j] == 0 && ip[2*j+1] == 0 {
+				j++
+			}
+			if j-i >= 2 && j-i > zEnd-zStart {
+				zStart, zEnd = i, j
+			}
+			i = j
+		}
+	}
+
+	digits := "0123456789abcdef"
+	if opts.Uppercase {
+		digits = "0123456789ABCDEF"
+	}
+	for i := 0; i < groups; i++ {
+		if i == zStart {
+			b.WriteString("::")
+			i = zEnd - 1
+			continue
+		}
+		if i > 0 && i != zEnd {
+			b.WriteByte(':')
+		}
+		g := uint16(ip[2*i])<<8 | uint16(ip[2*i+1])
+		for shift, started := 12, opts.Format == FormatExploded; shift >= 0; shift -= 4 {
+			d := (g >> uint(shift)) & 0xf
+			if d == 0 && !started && shift > 0 {
+				continue
+			}
+			started = true
+			b.WriteByte(digits[d])
+		}
+	}
+	if trailing && zEnd != groups {
+		b.WriteByte(':')
+	}
+}
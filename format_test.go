@@ -0,0 +1,111 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleFormatIP() {
+	ip := net.ParseIP("2001:db8::1")
+	for _, f := range []ipx.IPFormat{ipx.FormatCanonical, ipx.FormatExploded, ipx.FormatMixed, ipx.FormatHex} {
+		fmt.Println(ipx.FormatIP(ip, ipx.FormatOptions{Format: f}))
+	}
+	// Output:
+	// 2001:db8::1
+	// 2001:0db8:0000:0000:0000:0000:0000:0001
+	// 2001:db8::0.0.0.1
+	// 0x20010db8000000000000000000000001
+}
+
+func TestFormatIP(t *testing.T) {
+	for _, c := range []struct {
+		ip       string
+		opts     ipx.FormatOptions
+		expected string
+	}{
+		{"2001:db8:0:0:1:0:0:1", ipx.FormatOptions{}, "2001:db8::1:0:0:1"},
+		{"2001:db8:0:1:1:1:1:1", ipx.FormatOptions{}, "2001:db8:0:1:1:1:1:1"},
+		{"2001:0:0:1:0:0:0:1", ipx.FormatOptions{}, "2001:0:0:1::1"},
+		{"::", ipx.FormatOptions{}, "::"},
+		{"::1", ipx.FormatOptions{}, "::1"},
+		{"1::", ipx.FormatOptions{}, "1::"},
+		{"2001:DB8::ABCD", ipx.FormatOptions{}, "2001:db8::abcd"},
+		{"2001:db8::abcd", ipx.FormatOptions{Uppercase: true}, "2001:DB8::ABCD"},
+		{"192.0.2.1", ipx.FormatOptions{}, "192.0.2.1"},
+
+		{"2001:db8::1", ipx.FormatOptions{Format: ipx.FormatExploded}, "2001:0db8:0000:0000:0000:0000:0000:0001"},
+		{"::", ipx.FormatOptions{Format: ipx.FormatExploded}, "0000:0000:0000:0000:0000:0000:0000:0000"},
+		{"192.0.2.1", ipx.FormatOptions{Format: ipx.FormatExploded}, "192.0.2.1"},
+
+		{"192.0.2.1", ipx.FormatOptions{Format: ipx.FormatMixed}, "::ffff:192.0.2.1"},
+		{"192.0.2.1", ipx.FormatOptions{Format: ipx.FormatMixed, Uppercase: true}, "::FFFF:192.0.2.1"},
+		{"64:ff9b::c000:201", ipx.FormatOptions{Format: ipx.FormatMixed}, "64:ff9b::192.0.2.1"},
+		{"::c000:201", ipx.FormatOptions{Format: ipx.FormatMixed}, "::192.0.2.1"},
+		{"1:2:3:4:5:6:c000:201", ipx.FormatOptions{Format: ipx.FormatMixed}, "1:2:3:4:5:6:192.0.2.1"},
+
+		{"10.0.0.1", ipx.FormatOptions{Format: ipx.FormatDecimal}, "167772161"},
+		{"10.0.0.1", ipx.FormatOptions{Format: ipx.FormatHex}, "0x0a000001"},
+		{"10.0.0.1", ipx.FormatOptions{Format: ipx.FormatHex, Uppercase: true}, "0x0A000001"},
+		{"10.0.0.1", ipx.FormatOptions{Format: ipx.FormatBinary}, "0b00001010000000000000000000000001"},
+		{"::1", ipx.FormatOptions{Format: ipx.FormatDecimal}, "1"},
+		{"::1", ipx.FormatOptions{Format: ipx.FormatHex}, "0x00000000000000000000000000000001"},
+	} {
+		t.Run(fmt.Sprintf("%v %v", c.ip, c.opts), func(t *testing.T) {
+			if got := ipx.FormatIP(net.ParseIP(c.ip), c.opts); got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+
+	if got := ipx.FormatIP(nil, ipx.FormatOptions{}); got != "<nil>" {
+		t.Fatalf("expected <nil> but got %v", got)
+	}
+}
+
+func TestFormatIPMatchesStdlib(t *testing.T) {
+	ip := make(net.IP, net.IPv6len)
+	for i := 0; i < 1<<16; i++ {
+		// exercise every arrangement of zero and non-zero groups
+		for g := 0; g < 8; g++ {
+			ip[2*g], ip[2*g+1] = 0, 0
+			if i&(1<<uint(g)) != 0 {
+				ip[2*g+1] = byte(g + 1)
+			}
+		}
+		if ip.To4() != nil {
+			continue
+		}
+		if got, expected := ipx.FormatIP(ip, ipx.FormatOptions{}), ip.String(); got != expected {
+			t.Fatalf("expected %v but got %v", expected, got)
+		}
+	}
+}
+
+func TestFormatNet(t *testing.T) {
+	for _, c := range []struct {
+		net      string
+		opts     ipx.FormatOptions
+		expected string
+	}{
+		{"2001:db8::/32", ipx.FormatOptions{}, "2001:db8::/32"},
+		{"2001:db8::/32", ipx.FormatOptions{Format: ipx.FormatExploded}, "2001:0db8:0000:0000:0000:0000:0000:0000/32"},
+		{"192.0.2.0/24", ipx.FormatOptions{Format: ipx.FormatMixed}, "::ffff:192.0.2.0/120"},
+		{"192.0.2.0/24", ipx.FormatOptions{Format: ipx.FormatDecimal}, "3221225984/24"},
+		{"::ffff:10.0.0.0/104", ipx.FormatOptions{}, "10.0.0.0/8"},
+		{"::ffff:10.0.0.0/104", ipx.FormatOptions{Format: ipx.FormatMixed}, "::ffff:10.0.0.0/104"},
+		{"::ffff:10.0.0.0/104", ipx.FormatOptions{Format: ipx.FormatDecimal}, "167772160/8"},
+	} {
+		t.Run(fmt.Sprintf("%v %v", c.net, c.opts), func(t *testing.T) {
+			if got := ipx.FormatNet(cidr(c.net), c.opts); got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+
+	short := &net.IPNet{IP: net.ParseIP("::ffff:10.0.0.1"), Mask: net.CIDRMask(64, 128)}
+	if got := ipx.FormatNet(short, ipx.FormatOptions{}); got != "::ffff:10.0.0.1/64" {
+		t.Fatalf("expected ::ffff:10.0.0.1/64 but got %v", got)
+	}
+}
@@ -101,7 +101,7 @@ func ExampleIncrIP() {
 	// 0.0.1.1
 }
 
-func ExampleIncrIP_IP6() {
+func ExampleIncrIP_ip6() {
 	ip := net.ParseIP("::")
 	ipx.IncrIP(ip, 1<<32)
 	fmt.Println(ip)
@@ -171,7 +171,7 @@ func ExampleIncrNet() {
 	// 10.2.0.0/16
 }
 
-func ExampleIncrNet_IP6() {
+func ExampleIncrNet_ip6() {
 	ipN := cidr("::/32")
 	ipx.IncrNet(ipN, 2)
 	fmt.Println(ipN)
@@ -224,3 +224,11 @@ func cidr(cidrS string) *net.IPNet {
 	_, ipNet, _ := net.ParseCIDR(cidrS)
 	return ipNet
 }
+
+func cidrs(cidrS []string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrS))
+	for _, s := range cidrS {
+		nets = append(nets, cidr(s))
+	}
+	return nets
+}
@@ -0,0 +1,165 @@
+package ipx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+)
+
+// NetInfo describes a network in the manner of the ipcalc tool.
+type NetInfo struct {
+	Network   *net.IPNet
+	Netmask   net.IPMask
+	Wildcard  net.IPMask // the netmask inverted, as used by Cisco ACLs
+	PrefixLen int
+	Broadcast net.IP
+	// FirstHost and LastHost are the bounds of the addresses returned by Hosts.
+	FirstHost, LastHost net.IP
+	// NumAddresses and NumHosts are the numbers of addresses in the network and returned by Hosts.
+	NumAddresses, NumHosts Uint128
+	// Class is the historic class of an IPv4 network, from "A" to "E", or empty for IPv6 networks and IPv4 networks
+	// spanning several classes.
+	Class   string
+	Special *SpecialBlock
+	// ReverseZone is the reverse DNS zone at the longest octet (IPv4) or nibble (IPv6) boundary containing the network.
+	ReverseZone string
+}
+
+// Info returns a description of the network. Host bits set in ipNet are ignored. It returns nil if the network's mask
+// is not contiguous.
+func Info(ipNet *net.IPNet) *NetInfo {
+	n := Canonicalize(ipNet)
+	if n == nil {
+		return nil
+	}
+	ones, bits := n.Mask.Size()
+
+	info := &NetInfo{
+		Network:     n,
+		Netmask:     n.Mask,
+		Wildcard:    make(net.IPMask, len(n.Mask)),
+		PrefixLen:   ones,
+		Broadcast:   Broadcast(n),
+		Class:       class(n),
+		Special:     SpecialPurpose(n),
+		ReverseZone: reverseZone(n),
+	}
+	for i, b := range n.Mask {
+		info.Wildcard[i] = ^b
+	}
+
+	if bits-ones == 128 {
+		info.NumAddresses = Uint128{maxUint64, maxUint64}
+	} else {
+		info.NumAddresses = Uint128{0, 1}.Lsh(uint(bits - ones))
+	}
+
+	first, last, _ := Hosts(n).bounds()
+	info.NumHosts = last.Minus(first).Add(Uint128{0, 1})
+	info.FirstHost = Uint128ToIP(first, bits == 8*net.IPv6len)
+	info.LastHost = Uint128ToIP(last, bits == 8*net.IPv6len)
+	if bits == 8*net.IPv4len {
+		info.FirstHost, info.LastHost = info.FirstHost.To4(), info.LastHost.To4()
+	}
+
+	return info
+}
+
+func class(n *net.IPNet) string {
+	if len(n.IP) != net.IPv4len {
+		return ""
+	}
+	ones, _ := n.Mask.Size()
+	// the class is determined by the leading bits of the address, so the network must fix at least that many
+	for i, c := range []string{"A", "B", "C", "D"} {
+		if n.IP[0]&(0x80>>uint(i)) == 0 {
+			if ones < i+1 {
+				return ""
+			}
+			return c
+		}
+	}
+	if ones < 4 {
+		return ""
+	}
+	return "E"
+}
+
+func reverseZone(n *net.IPNet) string {
+	ones, bits := n.Mask.Size()
+	ptr := ReversePointer(n.IP)
+
+	// each label of the pointer represents an octet of an IPv4 address or a nibble of an IPv6 address
+	labelBits := 8
+	if bits == 8*net.IPv6len {
+		labelBits = 4
+	}
+	for skip := (bits - ones/labelBits*labelBits) / labelBits; skip > 0; skip-- {
+		ptr = ptr[strings.IndexByte(ptr, '.')+1:]
+	}
+	return ptr
+}
+
+// String returns a multi-line description of the network.
+func (i *NetInfo) String() string {
+	var b strings.Builder
+	line := func(label string, value interface{}) {
+		fmt.Fprintf(&b, "%-11s %v\n", label+":", value)
+	}
+	line("Network", i.Network)
+	line("Netmask", fmt.Sprintf("%v = %v", net.IP(i.Netmask), i.PrefixLen))
+	line("Wildcard", net.IP(i.Wildcard))
+	line("Broadcast", i.Broadcast)
+	line("HostMin", i.FirstHost)
+	line("HostMax", i.LastHost)
+	line("Addresses", i.NumAddresses)
+	line("Hosts", i.NumHosts)
+	if i.Class != "" {
+		line("Class", i.Class)
+	}
+	if i.Special != nil {
+		line("Special", fmt.Sprintf("%v %v (%v)", i.Special.Net, i.Special.Name, i.Special.RFC))
+	}
+	line("Reverse", i.ReverseZone)
+	return b.String()
+}
+
+// MarshalJSON implements json.Marshaler, writing addresses, networks and masks as text.
+func (i *NetInfo) MarshalJSON() ([]byte, error) {
+	type special struct {
+		Network string `json:"network"`
+		Name    string `json:"name"`
+		RFC     string `json:"rfc"`
+	}
+	doc := struct {
+		Network      string   `json:"network"`
+		Netmask      string   `json:"netmask"`
+		Wildcard     string   `json:"wildcard"`
+		PrefixLen    int      `json:"prefix_len"`
+		Broadcast    string   `json:"broadcast"`
+		FirstHost    net.IP   `json:"first_host"`
+		LastHost     net.IP   `json:"last_host"`
+		NumAddresses Uint128  `json:"num_addresses"`
+		NumHosts     Uint128  `json:"num_hosts"`
+		Class        string   `json:"class,omitempty"`
+		Special      *special `json:"special,omitempty"`
+		ReverseZone  string   `json:"reverse_zone"`
+	}{
+		Network:      i.Network.String(),
+		Netmask:      net.IP(i.Netmask).String(),
+		Wildcard:     net.IP(i.Wildcard).String(),
+		PrefixLen:    i.PrefixLen,
+		Broadcast:    i.Broadcast.String(),
+		FirstHost:    i.FirstHost,
+		LastHost:     i.LastHost,
+		NumAddresses: i.NumAddresses,
+		NumHosts:     i.NumHosts,
+		Class:        i.Class,
+		ReverseZone:  i.ReverseZone,
+	}
+	if i.Special != nil {
+		doc.Special = &special{i.Special.Net.String(), i.Special.Name, i.Special.RFC}
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,144 @@
+package ipx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleInfo() {
+	fmt.Print(ipx.Info(cidr("192.0.2.0/24")))
+	// Output:
+	// Network:    192.0.2.0/24
+	// Netmask:    255.255.255.0 = 24
+	// Wildcard:   0.0.0.255
+	// Broadcast:  192.0.2.255
+	// HostMin:    192.0.2.1
+	// HostMax:    192.0.2.254
+	// Addresses:  256
+	// Hosts:      254
+	// Class:      C
+	// Special:    192.0.2.0/24 Documentation (TEST-NET-1) (RFC 5737)
+	// Reverse:    2.0.192.in-addr.arpa
+}
+
+func TestInfo(t *testing.T) {
+	for _, c := range []struct {
+		net                             string
+		broadcast, first, last, class   string
+		addresses, hosts, special, zone string
+	}{
+		{
+			"10.1.2.3/8", "10.255.255.255", "10.0.0.1", "10.255.255.254", "A",
+			"16777216", "16777214", "Private-Use", "10.in-addr.arpa",
+		},
+		{
+			"172.16.0.0/22", "172.16.3.255", "172.16.0.1", "172.16.3.254", "B",
+			"1024", "1022", "Private-Use", "16.172.in-addr.arpa",
+		},
+		{
+			"224.0.0.0/4", "239.255.255.255", "224.0.0.1", "239.255.255.254", "D",
+			"268435456", "268435454", "Multicast", "in-addr.arpa",
+		},
+		{
+			"192.0.2.4/31", "192.0.2.5", "192.0.2.4", "192.0.2.5", "C",
+			"2", "2", "Documentation (TEST-NET-1)", "2.0.192.in-addr.arpa",
+		},
+		{
+			"192.0.2.4/32", "192.0.2.4", "192.0.2.4", "192.0.2.4", "C",
+			"1", "1", "Documentation (TEST-NET-1)", "4.2.0.192.in-addr.arpa",
+		},
+		{
+			"0.0.0.0/0", "255.255.255.255", "0.0.0.1", "255.255.255.254", "",
+			"4294967296", "4294967294", "", "in-addr.arpa",
+		},
+		{
+			"2001:db8::/62", "2001:db8::3:ffff:ffff:ffff:ffff", "2001:db8::1", "2001:db8::3:ffff:ffff:ffff:ffff", "",
+			"73786976294838206464", "73786976294838206463", "Documentation", "0.0.0.0.0.0.0.8.b.d.0.1.0.0.2.ip6.arpa",
+		},
+		{
+			"::/0", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "::1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "",
+			"340282366920938463463374607431768211455", "340282366920938463463374607431768211455", "", "ip6.arpa",
+		},
+	} {
+		t.Run(c.net, func(t *testing.T) {
+			ip, ipN, _ := net.ParseCIDR(c.net)
+			ipN.IP = ip // host bits should be ignored
+			i := ipx.Info(ipN)
+
+			var special string
+			if i.Special != nil {
+				special = i.Special.Name
+			}
+			got := fmt.Sprintf(
+				"%v %v %v %q %v %v %q %v",
+				i.Broadcast, i.FirstHost, i.LastHost, i.Class, i.NumAddresses, i.NumHosts, special, i.ReverseZone,
+			)
+			expected := fmt.Sprintf(
+				"%v %v %v %q %v %v %q %v",
+				net.ParseIP(c.broadcast), net.ParseIP(c.first), net.ParseIP(c.last), c.class, c.addresses, c.hosts,
+				c.special, c.zone,
+			)
+			if got != expected {
+				t.Fatalf("expected %v but got %v", expected, got)
+			}
+		})
+	}
+
+	if i := ipx.Info(&net.IPNet{IP: net.ParseIP("10.0.0.0"), Mask: net.IPv4Mask(255, 0, 255, 0)}); i != nil {
+		t.Fatalf("expected nil for non-contiguous mask but got %v", i)
+	}
+}
+
+func TestInfoCountsOfEverything(t *testing.T) {
+	// ::/0 has 2^128 addresses and 2^128-1 hosts, but the number of addresses is capped at the largest Uint128
+	max := ipx.Uint128{H: ^uint64(0), L: ^uint64(0)}
+	if i := ipx.Info(cidr("::/0")); i.NumAddresses != max || i.NumHosts != max {
+		t.Fatalf("expected %v addresses and hosts but got %v and %v", max, i.NumAddresses, i.NumHosts)
+	}
+	half := ipx.Uint128{H: 1 << 63}
+	if i := ipx.Info(cidr("::/1")); i.NumAddresses != half || i.NumHosts != half.Minus(ipx.Uint128{L: 1}) {
+		t.Fatalf("expected %v addresses and one fewer hosts but got %v and %v", half, i.NumAddresses, i.NumHosts)
+	}
+}
+
+func TestInfoJSON(t *testing.T) {
+	b, err := json.Marshal(ipx.Info(cidr("198.51.100.0/30")))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := `{"network":"198.51.100.0/30","netmask":"255.255.255.252","wildcard":"0.0.0.3",` +
+		`"prefix_len":30,"broadcast":"198.51.100.3","first_host":"198.51.100.1","last_host":"198.51.100.2",` +
+		`"num_addresses":"4","num_hosts":"2","class":"C",` +
+		`"special":{"network":"198.51.100.0/24","name":"Documentation (TEST-NET-2)","rfc":"RFC 5737"},` +
+		`"reverse_zone":"100.51.198.in-addr.arpa"}`
+	if string(b) != expected {
+		t.Fatalf("expected %v but got %v", expected, string(b))
+	}
+}
+
+func TestSpecialPurpose(t *testing.T) {
+	for _, c := range []struct {
+		net, expected string
+	}{
+		{"192.0.0.9/32", "192.0.0.9/32"},
+		{"192.0.0.64/26", "192.0.0.0/24"},
+		{"10.0.0.0/7", "<nil>"},
+		{"8.8.8.8/32", "<nil>"},
+		{"2001:db8:1::/48", "2001:db8::/32"},
+		{"2001:1::1/128", "2001:1::1/128"},
+		{"::1/128", "::1/128"},
+	} {
+		t.Run(c.net, func(t *testing.T) {
+			var got interface{} = "<nil>"
+			if b := ipx.SpecialPurpose(cidr(c.net)); b != nil {
+				got = b.Net
+			}
+			if fmt.Sprint(got) != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
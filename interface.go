@@ -0,0 +1,135 @@
+package ipx
+
+import (
+	"errors"
+	"net"
+	"strconv"
+)
+
+// Interface is a host address together with the prefix length of the network it belongs to, such as 192.0.2.5/24,
+// like Python's IPv4Interface and IPv6Interface. Unlike a *net.IPNet, the host part of the address is never lost.
+type Interface struct {
+	ip   net.IP
+	mask net.IPMask
+}
+
+// NewInterface returns the interface for the address and mask. It returns an error if the mask is not contiguous or
+// doesn't match the address's IP version.
+func NewInterface(ip net.IP, mask net.IPMask) (Interface, error) {
+	n := Canonicalize(&net.IPNet{IP: ip, Mask: mask})
+	if n == nil {
+		return Interface{}, errors.New("invalid interface address or mask")
+	}
+	ip, _ = ipAndBits(ip)
+	return Interface{append(net.IP(nil), ip...), n.Mask}, nil
+}
+
+// InterfaceFromNet returns the interface described by a *net.IPNet whose host bits are set, as returned by
+// net.Interface.Addrs.
+func InterfaceFromNet(ipNet *net.IPNet) (Interface, error) {
+	return NewInterface(ipNet.IP, ipNet.Mask)
+}
+
+// ParseInterface parses s as an interface in any form accepted by ParseNetLenient, e.g. 192.0.2.5/24,
+// 192.0.2.5/255.255.255.0, or 192.0.2.5 for 192.0.2.5/32.
+func ParseInterface(s string) (Interface, error) {
+	ip, ipNet, err := ParseNetLenient(s)
+	if err != nil {
+		return Interface{}, err
+	}
+	return NewInterface(ip, ipNet.Mask)
+}
+
+// IP returns the host address. IPv4 addresses are returned in their 4 byte form.
+func (i Interface) IP() net.IP {
+	return append(net.IP(nil), i.ip...)
+}
+
+// PrefixLen returns the prefix length of the interface's network.
+func (i Interface) PrefixLen() int {
+	ones, _ := i.mask.Size()
+	return ones
+}
+
+// Network returns the network the address belongs to.
+func (i Interface) Network() *net.IPNet {
+	return &net.IPNet{IP: i.ip.Mask(i.mask), Mask: append(net.IPMask(nil), i.mask...)}
+}
+
+// IPNet returns the address and mask as a *net.IPNet with the host bits set, as used by net.Interface.Addrs.
+func (i Interface) IPNet() *net.IPNet {
+	return &net.IPNet{IP: i.IP(), Mask: append(net.IPMask(nil), i.mask...)}
+}
+
+// Broadcast returns the broadcast address of the interface's network.
+func (i Interface) Broadcast() net.IP {
+	return Broadcast(i.Network())
+}
+
+// Hosts returns the usable addresses of the interface's network, as Hosts does.
+func (i Interface) Hosts() *IPIter {
+	return Hosts(i.Network())
+}
+
+// IsHost returns whether the address is one of those returned by Hosts, i.e. is usable by a host.
+func (i Interface) IsHost() bool {
+	first, last, ok := i.Hosts().bounds()
+	if !ok {
+		return false
+	}
+	ip, _ := IPToUint128(i.ip)
+	return ip.Cmp(first) != -1 && ip.Cmp(last) != 1
+}
+
+// IsNetworkAddress returns whether the address is the first address of its network. This address is reserved in
+// IPv4 networks larger than /31 and is the subnet-router anycast address in IPv6 networks larger than /127.
+func (i Interface) IsNetworkAddress() bool {
+	return i.ip.Equal(i.ip.Mask(i.mask))
+}
+
+// IsBroadcast returns whether the address is the broadcast address of an IPv4 network larger than /31. Point-to-point
+// and single address networks and IPv6 networks have no broadcast address.
+func (i Interface) IsBroadcast() bool {
+	ones, bits := i.mask.Size()
+	return bits == 8*net.IPv4len && bits-ones > 1 && i.ip.Equal(i.Broadcast())
+}
+
+// In returns whether the interface's network is a subnet of ipNet, as IsSubnet does.
+func (i Interface) In(ipNet *net.IPNet) bool {
+	return IsSubnet(ipNet, i.Network())
+}
+
+// Equal returns whether both the address and prefix of the interfaces are the same.
+func (i Interface) Equal(o Interface) bool {
+	return i.ip.Equal(o.ip) && i.PrefixLen() == o.PrefixLen() && len(i.mask) == len(o.mask)
+}
+
+// String returns the address and prefix length in CIDR notation, e.g. 192.0.2.5/24.
+func (i Interface) String() string {
+	if i.ip == nil {
+		return "<nil>"
+	}
+	return i.ip.String() + "/" + strconv.Itoa(i.PrefixLen())
+}
+
+// MarshalText implements encoding.TextMarshaler using CIDR notation.
+func (i Interface) MarshalText() ([]byte, error) {
+	if i.ip == nil {
+		return []byte(""), nil
+	}
+	return []byte(i.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the forms of ParseInterface.
+func (i *Interface) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*i = Interface{}
+		return nil
+	}
+	v, err := ParseInterface(string(text))
+	if err != nil {
+		return err
+	}
+	*i = v
+	return nil
+}
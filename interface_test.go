@@ -0,0 +1,92 @@
+package ipx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleInterface() {
+	iface, _ := ipx.ParseInterface("192.0.2.5/24")
+	fmt.Println(iface.IP(), iface.Network(), iface.Broadcast(), iface.IsHost())
+	// Output:
+	// 192.0.2.5 192.0.2.0/24 192.0.2.255 true
+}
+
+func TestInterface(t *testing.T) {
+	for _, c := range []struct {
+		in, ip, network                      string
+		host, networkAddress, broadcast, in6 bool
+	}{
+		{"192.0.2.5/24", "192.0.2.5", "192.0.2.0/24", true, false, false, false},
+		{"192.0.2.0/24", "192.0.2.0", "192.0.2.0/24", false, true, false, false},
+		{"192.0.2.255/24", "192.0.2.255", "192.0.2.0/24", false, false, true, false},
+		{"192.0.2.5/255.255.255.252", "192.0.2.5", "192.0.2.4/30", true, false, false, false},
+		{"192.0.2.4/31", "192.0.2.4", "192.0.2.4/31", true, true, false, false},
+		{"192.0.2.5/31", "192.0.2.5", "192.0.2.4/31", true, false, false, false},
+		{"192.0.2.5", "192.0.2.5", "192.0.2.5/32", true, true, false, false},
+		{"2001:db8::/64", "2001:db8::", "2001:db8::/64", false, true, false, true},
+		{"2001:db8::ffff:ffff:ffff:ffff/64", "2001:db8::ffff:ffff:ffff:ffff", "2001:db8::/64", true, false, false, true},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			iface, err := ipx.ParseInterface(c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			got := fmt.Sprintln(iface.IP(), iface.Network(), iface.IsHost(), iface.IsNetworkAddress(), iface.IsBroadcast())
+			expected := fmt.Sprintln(c.ip, cidr(c.network), c.host, c.networkAddress, c.broadcast)
+			if got != expected {
+				t.Fatalf("expected %v but got %v", expected, got)
+			}
+			if !iface.In(cidr(c.network)) {
+				t.Fatalf("expected interface to be in %v", c.network)
+			}
+			if iface.In(cidr("198.51.100.0/24")) {
+				t.Fatal("expected interface not to be in 198.51.100.0/24")
+			}
+
+			ipNet := iface.IPNet()
+			if !ipNet.IP.Equal(net.ParseIP(c.ip)) {
+				t.Fatalf("expected IPNet to keep host address %v but got %v", c.ip, ipNet.IP)
+			}
+			fromNet, err := ipx.InterfaceFromNet(ipNet)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !fromNet.Equal(iface) {
+				t.Fatalf("expected %v but got %v", iface, fromNet)
+			}
+		})
+	}
+
+	if _, err := ipx.NewInterface(net.ParseIP("10.0.0.1"), net.IPv4Mask(255, 0, 255, 0)); err == nil {
+		t.Fatal("expected error for non-contiguous mask")
+	}
+	if _, err := ipx.ParseInterface("10.0.0.1/33"); err == nil {
+		t.Fatal("expected error for invalid prefix")
+	}
+}
+
+func TestInterfaceText(t *testing.T) {
+	type config struct {
+		Address ipx.Interface `json:"address"`
+	}
+	var c config
+	if err := json.Unmarshal([]byte(`{"address":"10.0.0.5/255.255.255.0"}`), &c); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := json.Marshal(c)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"address":"10.0.0.5/24"}`; string(b) != expected {
+		t.Fatalf("expected %v but got %v", expected, string(b))
+	}
+
+	var zero ipx.Interface
+	if s := zero.String(); s != "<nil>" {
+		t.Fatalf("expected <nil> but got %v", s)
+	}
+}
@@ -11,16 +11,17 @@ const (
 )
 
 type v4IPIter struct {
-	val, incr, limit uint32
+	first, val, incr, last uint32
 }
 
 type v6IPIter struct {
-	val, incr, limit Uint128
+	first, val, incr, last Uint128
 }
 
 const (
 	ipIterFlagV6 = 1 << iota
 	ipIterFlagNegative
+	ipIterFlagActive // set while there are values remaining
 )
 
 // IPIter permits iteration over a series of ips. It is always start inclusive.
@@ -29,7 +30,7 @@ type IPIter struct {
 	v6    v6IPIter
 	flags uint8
 
-	ip net.IP
+	ip, peek net.IP
 }
 
 // IP returns the most recent IP; the underlying type may be modified on later calls to `Next`.
@@ -40,41 +41,181 @@ func (i *IPIter) IP() net.IP {
 
 // Next returns true when the underlying pointer has been successfully updated with the next value.
 func (i *IPIter) Next() bool {
+	if i.flags&ipIterFlagActive == 0 {
+		return false
+	}
 	if i.flags&ipIterFlagV6 > 0 {
+		From128(i.v6.val, i.ip)
+		// compare the distance remaining to the step rather than stepping past the last value, which might overflow
 		if i.flags&ipIterFlagNegative > 0 {
-			if i.v6.val.Cmp(i.v6.limit) != 1 {
-				return false
-			}
-			From128(i.v6.val, i.ip)
-			old := i.v6.val
-			if i.v6.val = i.v6.val.Minus(i.v6.incr); old.Cmp(i.v6.val) == -1 {
-				i.v6.val = Uint128{0, 0}
+			if i.v6.val.Minus(i.v6.last).Cmp(i.v6.incr) == -1 {
+				i.flags &^= ipIterFlagActive
+				return true
 			}
+			i.v6.val = i.v6.val.Minus(i.v6.incr)
 			return true
 		}
-		if i.v6.val.Cmp(i.v6.limit) != -1 {
-			return false
+		if i.v6.last.Minus(i.v6.val).Cmp(i.v6.incr) == -1 {
+			i.flags &^= ipIterFlagActive
+			return true
 		}
-		From128(i.v6.val, i.ip)
 		i.v6.val = i.v6.val.Add(i.v6.incr)
 		return true
 	}
+	from32(i.v4.val, i.ip)
 	if i.flags&ipIterFlagNegative > 0 {
-		if i.v4.val <= i.v4.limit {
-			return false
+		if i.v4.val-i.v4.last < i.v4.incr {
+			i.flags &^= ipIterFlagActive
+			return true
 		}
-		from32(i.v4.val, i.ip)
 		i.v4.val -= i.v4.incr
 		return true
 	}
-	if i.v4.val >= i.v4.limit {
-		return false
+	if i.v4.last-i.v4.val < i.v4.incr {
+		i.flags &^= ipIterFlagActive
+		return true
 	}
-	from32(i.v4.val, i.ip)
 	i.v4.val += i.v4.incr
 	return true
 }
 
+// Peek returns the value the next call to `Next` will make current, or nil if there is none. The underlying type may
+// be modified on later calls to `Peek`.
+func (i *IPIter) Peek() net.IP {
+	if i.flags&ipIterFlagActive == 0 {
+		return nil
+	}
+	if i.peek == nil {
+		i.peek = make(net.IP, len(i.ip))
+		copy(i.peek, i.ip)
+	}
+	if i.flags&ipIterFlagV6 > 0 {
+		From128(i.v6.val, i.peek)
+	} else {
+		from32(i.v4.val, i.peek)
+	}
+	return i.peek
+}
+
+// Len returns the number of values the iter yields in total.
+func (i *IPIter) Len() Uint128 {
+	if i.ip == nil {
+		return Uint128{}
+	}
+	return i.count(Uint128{})
+}
+
+// Remaining returns the number of values the iter has yet to yield.
+func (i *IPIter) Remaining() Uint128 {
+	if i.flags&ipIterFlagActive == 0 {
+		return Uint128{}
+	}
+	_, next, _, _ := i.params()
+	return i.count(i.index(next))
+}
+
+// Reset moves the iter back to its first value.
+func (i *IPIter) Reset() {
+	if i.ip != nil {
+		i.moveTo(Uint128{})
+	}
+}
+
+// Skip moves the iter past the next n values without yielding them, returning whether any values remain.
+func (i *IPIter) Skip(n Uint128) bool {
+	if i.flags&ipIterFlagActive == 0 {
+		return false
+	}
+	_, next, _, _ := i.params()
+	idx, overflow := i.index(next).AddOverflow(n)
+	if overflow {
+		i.flags &^= ipIterFlagActive
+		return false
+	}
+	return i.moveTo(idx)
+}
+
+// Seek moves the iter so that its next value is the first of all its values, including those already yielded, which
+// is ip or comes after ip in the direction of iteration. It returns false, exhausting the iter, if there is no such
+// value. If ip is of the other IP version, the iter is unchanged and false is returned.
+func (i *IPIter) Seek(ip net.IP) bool {
+	u, err := IPToUint128(ip)
+	if err != nil || i.ip == nil || (ip.To4() == nil) != (i.flags&ipIterFlagV6 > 0) {
+		return false
+	}
+	first, _, incr, _ := i.params()
+	cmp := u.Cmp(first)
+	if i.flags&ipIterFlagNegative > 0 {
+		cmp = -cmp
+	}
+	if cmp != 1 {
+		return i.moveTo(Uint128{})
+	}
+	// round up to the next value on or after ip
+	q, r := i.distance(u).QuoRem(incr)
+	if !r.IsZero() {
+		q = q.Add(Uint128{0, 1})
+	}
+	return i.moveTo(q)
+}
+
+// params returns the first, next and last values of the iter and its step as integers.
+func (i *IPIter) params() (first, next, incr, last Uint128) {
+	if i.flags&ipIterFlagV6 > 0 {
+		return i.v6.first, i.v6.val, i.v6.incr, i.v6.last
+	}
+	return Uint128{0, uint64(i.v4.first)},
+		Uint128{0, uint64(i.v4.val)},
+		Uint128{0, uint64(i.v4.incr)},
+		Uint128{0, uint64(i.v4.last)}
+}
+
+// distance returns the distance from the first value of the iter to u, which must not come before it.
+func (i *IPIter) distance(u Uint128) Uint128 {
+	first, _, _, _ := i.params()
+	if i.flags&ipIterFlagNegative > 0 {
+		return first.Minus(u)
+	}
+	return u.Minus(first)
+}
+
+// index returns the number of steps from the first value of the iter to u, which must be one of its values.
+func (i *IPIter) index(u Uint128) Uint128 {
+	_, _, incr, _ := i.params()
+	return i.distance(u).Div(incr)
+}
+
+// count returns the number of values from the one at index idx to the last, saturating at the maximum Uint128.
+func (i *IPIter) count(idx Uint128) Uint128 {
+	_, _, _, last := i.params()
+	n, overflow := i.index(last).Minus(idx).AddOverflow(Uint128{0, 1})
+	if overflow {
+		return Uint128{maxUint64, maxUint64}
+	}
+	return n
+}
+
+// moveTo makes the value idx steps from the first the next value of the iter, exhausting the iter and returning false
+// if there is no such value.
+func (i *IPIter) moveTo(idx Uint128) bool {
+	first, _, incr, last := i.params()
+	if idx.Cmp(i.index(last)) == 1 {
+		i.flags &^= ipIterFlagActive
+		return false
+	}
+	val := first.Add(idx.Mul(incr))
+	if i.flags&ipIterFlagNegative > 0 {
+		val = first.Minus(idx.Mul(incr))
+	}
+	if i.flags&ipIterFlagV6 > 0 {
+		i.v6.val = val
+	} else {
+		i.v4.val = uint32(val.L)
+	}
+	i.flags |= ipIterFlagActive
+	return true
+}
+
 // IterIP returns an iter for the given step from [start, end). If end is nil, it is set to the maximum type for
 // the version. If the step is zero, IP versions mismatch or the sign of the increment doesn't match that of
 // end - start, an empty iter is returned.
@@ -89,23 +230,25 @@ func IterIP(start net.IP, step int, end net.IP) *IPIter {
 	return resolveIPs6(start, step, end, 0)
 }
 
-func iterIPv4(val, incr, limit uint32) *IPIter {
-	iter := IPIter{ip: make(net.IP, len(net.IPv4zero)), v4: v4IPIter{val, incr, limit}}
+// iterIPv4 returns an iter stepping from val towards last, inclusive of both.
+func iterIPv4(val, incr, last uint32) *IPIter {
+	iter := IPIter{ip: make(net.IP, len(net.IPv4zero)), v4: v4IPIter{val, val, incr, last}, flags: ipIterFlagActive}
 	copy(iter.ip, net.IPv4zero)
-	if limit < val {
+	if last < val {
 		iter.flags |= ipIterFlagNegative
 	}
 	return &iter
 }
 
-func iterIPv6(val, incr, limit Uint128) *IPIter {
+// iterIPv6 returns an iter stepping from val towards last, inclusive of both.
+func iterIPv6(val, incr, last Uint128) *IPIter {
 	iter := IPIter{
 		ip:    make(net.IP, len(net.IPv6zero)),
-		v6:    v6IPIter{val, incr, limit},
-		flags: ipIterFlagV6,
+		v6:    v6IPIter{val, val, incr, last},
+		flags: ipIterFlagV6 | ipIterFlagActive,
 	}
 	copy(iter.ip, net.IPv6zero)
-	if limit.Cmp(val) == -1 {
+	if last.Cmp(val) == -1 {
 		iter.flags |= ipIterFlagNegative
 	}
 	return &iter
@@ -113,8 +256,9 @@ func iterIPv6(val, incr, limit Uint128) *IPIter {
 
 // NetIter permits iteration over a series of IP networks. It is always start inclusive.
 type NetIter struct {
-	ips IPIter
-	net *net.IPNet
+	ips  IPIter
+	net  *net.IPNet
+	peek net.IPNet
 }
 
 // Net returns the most recent IPNet; the underlying type may be modified on later calls to `Next`.
@@ -129,7 +273,48 @@ func (n *NetIter) Next() bool {
 	return n.ips.Next()
 }
 
-// IterNet returns an iterator for the given increment starting with the provided network
+// Peek returns the network the next call to `Next` will make current, or nil if there is none. The underlying type
+// may be modified on later calls to `Peek`.
+func (n *NetIter) Peek() *net.IPNet {
+	ip := n.ips.Peek()
+	if ip == nil {
+		return nil
+	}
+	n.peek = net.IPNet{IP: ip, Mask: n.net.Mask}
+	return &n.peek
+}
+
+// Len returns the number of networks the iter yields in total.
+func (n *NetIter) Len() Uint128 {
+	return n.ips.Len()
+}
+
+// Remaining returns the number of networks the iter has yet to yield.
+func (n *NetIter) Remaining() Uint128 {
+	return n.ips.Remaining()
+}
+
+// Reset moves the iter back to its first network.
+func (n *NetIter) Reset() {
+	n.ips.Reset()
+}
+
+// Skip moves the iter past the next n networks without yielding them, returning whether any networks remain.
+func (n *NetIter) Skip(count Uint128) bool {
+	return n.ips.Skip(count)
+}
+
+// Seek moves the iter so that its next network is the first of all its networks which contains ip or comes after it
+// in the direction of iteration, as IPIter.Seek does for addresses.
+func (n *NetIter) Seek(ip net.IP) bool {
+	if n.net == nil {
+		return false
+	}
+	return n.ips.Seek(ip.Mask(n.net.Mask))
+}
+
+// IterNet returns an iterator for the given increment starting with the provided network. Host bits set in start or
+// end are ignored.
 func IterNet(start *net.IPNet, step int, end *net.IPNet) *NetIter {
 	if step == 0 {
 		return new(NetIter)
@@ -140,8 +325,9 @@ func IterNet(start *net.IPNet, step int, end *net.IPNet) *NetIter {
 		if !bytes.Equal(start.Mask, end.Mask) {
 			return new(NetIter)
 		}
-		endIP = end.IP
+		endIP = end.IP.Mask(end.Mask)
 	}
+	startIP := start.IP.Mask(start.Mask)
 
 	mask := make(net.IPMask, len(start.Mask))
 	copy(mask, start.Mask)
@@ -149,10 +335,10 @@ func IterNet(start *net.IPNet, step int, end *net.IPNet) *NetIter {
 	ones, bits := mask.Size()
 	suffix := uint(bits - ones)
 
-	if start.IP.To4() != nil {
-		return &NetIter{*resolveIPs4(start.IP, step, endIP, suffix), &net.IPNet{Mask: mask}}
+	if startIP.To4() != nil {
+		return &NetIter{ips: *resolveIPs4(startIP, step, endIP, suffix), net: &net.IPNet{Mask: mask}}
 	}
-	return &NetIter{*resolveIPs6(start.IP, step, endIP, suffix), &net.IPNet{Mask: mask}}
+	return &NetIter{ips: *resolveIPs6(startIP, step, endIP, suffix), net: &net.IPNet{Mask: mask}}
 }
 
 func resolveIPs4(start net.IP, step int, end net.IP, shift uint) *IPIter {
@@ -168,7 +354,7 @@ func resolveIPs4(start net.IP, step int, end net.IP, shift uint) *IPIter {
 				return new(IPIter)
 			}
 		}
-		return iterIPv4(sIP, uint32(step)<<shift, eIP)
+		return iterIPv4(sIP, uint32(step)<<shift, eIP-1)
 	}
 	var eIP uint32
 	if end != nil {
@@ -180,7 +366,7 @@ func resolveIPs4(start net.IP, step int, end net.IP, shift uint) *IPIter {
 			return new(IPIter)
 		}
 	}
-	return iterIPv4(sIP, uint32(step*-1)<<shift, eIP)
+	return iterIPv4(sIP, uint32(step*-1)<<shift, eIP+1)
 }
 
 func resolveIPs6(start net.IP, step int, end net.IP, shift uint) *IPIter {
@@ -196,7 +382,7 @@ func resolveIPs6(start net.IP, step int, end net.IP, shift uint) *IPIter {
 				return new(IPIter)
 			}
 		}
-		return iterIPv6(sIP, Uint128{0, uint64(step)}.Lsh(shift), eIP)
+		return iterIPv6(sIP, Uint128{0, uint64(step)}.Lsh(shift), eIP.Minus(Uint128{0, 1}))
 	}
 	var eIP Uint128
 	if end != nil {
@@ -208,5 +394,106 @@ func resolveIPs6(start net.IP, step int, end net.IP, shift uint) *IPIter {
 			return new(IPIter)
 		}
 	}
-	return iterIPv6(sIP, Uint128{0, uint64(step * -1)}.Lsh(shift), eIP)
+	return iterIPv6(sIP, Uint128{0, uint64(step * -1)}.Lsh(shift), eIP.Add(Uint128{0, 1}))
+}
+
+// bounds returns the next and last values of the iter as integers, and whether any values remain.
+func (i *IPIter) bounds() (next, last Uint128, ok bool) {
+	if i.flags&ipIterFlagV6 > 0 {
+		return i.v6.val, i.v6.last, i.flags&ipIterFlagActive > 0
+	}
+	return Uint128{0, uint64(i.v4.val)}, Uint128{0, uint64(i.v4.last)}, i.flags&ipIterFlagActive > 0
+}
+
+// Shard returns the shard'th of k iters which divide the values remaining in the iter between them. Each shard yields
+// a contiguous run of the values in order, the runs are disjoint and together cover every value, and their lengths
+// differ by at most one, so shards may be handed to independent workers. The iter itself is not advanced. If k isn't
+// positive or shard isn't between 0 and k-1, an empty iter is returned.
+func (i *IPIter) Shard(k, shard int) *IPIter {
+	next, last, ok := i.bounds()
+	if !ok || k <= 0 || shard < 0 || shard >= k {
+		return new(IPIter)
+	}
+	incr := i.v6.incr
+	if i.flags&ipIterFlagV6 == 0 {
+		incr = Uint128{0, uint64(i.v4.incr)}
+	}
+	negative := i.flags&ipIterFlagNegative > 0
+	dist := last.Minus(next)
+	if negative {
+		dist = next.Minus(last)
+	}
+
+	// the values are indexed from 0 to m; counting them would overflow for every IPv6 address, so instead of dividing
+	// m+1 values, divide m and give the remainder plus one to the first shards
+	q, r := dist.Div(incr).QuoRem64(uint64(k))
+	s, extra := uint64(shard), r+1
+	start, size := q.Mul64(s), q // size is one less than the number of values in the shard
+	if s < extra {
+		start = start.Add(Uint128{0, s})
+	} else {
+		if q.IsZero() {
+			return new(IPIter)
+		}
+		start, size = start.Add(Uint128{0, extra}), q.Minus(Uint128{0, 1})
+	}
+
+	var first, end Uint128
+	if negative {
+		first = next.Minus(start.Mul(incr))
+		end = first.Minus(size.Mul(incr))
+	} else {
+		first = next.Add(start.Mul(incr))
+		end = first.Add(size.Mul(incr))
+	}
+	if i.flags&ipIterFlagV6 > 0 {
+		return iterIPv6(first, incr, end)
+	}
+	return iterIPv4(uint32(first.L), uint32(incr.L), uint32(end.L))
+}
+
+// Shard returns the shard'th of k iters which divide the networks remaining in the iter between them, as
+// IPIter.Shard does for addresses.
+func (n *NetIter) Shard(k, shard int) *NetIter {
+	ips := n.ips.Shard(k, shard)
+	if ips.flags&ipIterFlagActive == 0 {
+		return new(NetIter)
+	}
+	mask := make(net.IPMask, len(n.net.Mask))
+	copy(mask, n.net.Mask)
+	return &NetIter{ips: *ips, net: &net.IPNet{Mask: mask}}
+}
+
+// ChainIPIter permits iteration over several IPIters in turn.
+type ChainIPIter struct {
+	iters []*IPIter
+}
+
+// ChainIP returns an iter which yields the values of each of the provided iters in turn.
+func ChainIP(iters ...*IPIter) *ChainIPIter {
+	return &ChainIPIter{iters: iters}
+}
+
+// IP returns the most recent IP; the underlying type may be modified on later calls to `Next`.
+// It does no allocation.
+func (c *ChainIPIter) IP() net.IP {
+	if len(c.iters) == 0 {
+		return nil
+	}
+	return c.iters[0].IP()
+}
+
+// Next returns true when the underlying pointer has been successfully updated with the next value.
+func (c *ChainIPIter) Next() bool {
+	for len(c.iters) > 0 {
+		if c.iters[0].Next() {
+			return true
+		}
+		if len(c.iters) == 1 {
+			// keep the last iter so that IP continues to return its final value
+			return false
+		}
+		c.iters = c.iters[1:]
+	}
+	return false
 }
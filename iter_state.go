@@ -0,0 +1,118 @@
+package ipx
+
+import (
+	"errors"
+	"net"
+)
+
+// IPIterState records the values of an IPIter and how far it has progressed. It may be encoded, e.g. as JSON, and
+// later passed to RestoreIPIter to continue the iteration. The zero value is the state of an empty iter.
+type IPIterState struct {
+	// First is the first value of the iter and Last is the inclusive bound of its values.
+	First, Last net.IP
+	// Step is the distance between values, which count down when Last comes before First.
+	Step Uint128
+	// Next is the next value the iter will yield, or nil if it is exhausted.
+	Next net.IP
+}
+
+// State returns the state of the iter, from which RestoreIPIter recreates it.
+func (i *IPIter) State() IPIterState {
+	if i.ip == nil {
+		return IPIterState{}
+	}
+	v6 := i.flags&ipIterFlagV6 > 0
+	first, next, incr, last := i.params()
+	s := IPIterState{First: Uint128ToIP(first, v6), Last: Uint128ToIP(last, v6), Step: incr}
+	if i.flags&ipIterFlagActive > 0 {
+		s.Next = Uint128ToIP(next, v6)
+	}
+	return s
+}
+
+// RestoreIPIter returns an iter in the recorded state. It returns an error if the state is not one an iter could be
+// in.
+func RestoreIPIter(s IPIterState) (*IPIter, error) {
+	if s.First == nil && s.Last == nil && s.Next == nil && s.Step.IsZero() {
+		return new(IPIter), nil
+	}
+
+	first, errFirst := IPToUint128(s.First)
+	last, errLast := IPToUint128(s.Last)
+	switch {
+	case errFirst != nil || errLast != nil:
+		return nil, errors.New("invalid first or last IP")
+	case (s.First.To4() == nil) != (s.Last.To4() == nil):
+		return nil, errors.New("mismatched IP versions")
+	case s.Step.IsZero():
+		return nil, errors.New("step must be positive")
+	}
+
+	var iter *IPIter
+	if s.First.To4() != nil {
+		if s.Step.H != 0 || s.Step.L > maxUint32 {
+			return nil, errors.New("step too large for IPv4")
+		}
+		iter = iterIPv4(uint32(first.L), uint32(s.Step.L), uint32(last.L))
+	} else {
+		iter = iterIPv6(first, s.Step, last)
+	}
+
+	if s.Next == nil {
+		iter.flags &^= ipIterFlagActive
+		return iter, nil
+	}
+	next, err := IPToUint128(s.Next)
+	if err != nil || !iter.Seek(s.Next) {
+		return nil, errors.New("next IP is not a value of the iter")
+	}
+	if _, val, _, _ := iter.params(); val != next {
+		return nil, errors.New("next IP is not a value of the iter")
+	}
+	return iter, nil
+}
+
+// NetIterState records the networks of a NetIter and how far it has progressed, as IPIterState does for an IPIter.
+// The addresses are those of the networks.
+type NetIterState struct {
+	IPIterState
+	// Prefix is the prefix length of the networks.
+	Prefix int
+}
+
+// State returns the state of the iter, from which RestoreNetIter recreates it.
+func (n *NetIter) State() NetIterState {
+	if n.net == nil {
+		return NetIterState{}
+	}
+	ones, _ := n.net.Mask.Size()
+	return NetIterState{IPIterState: n.ips.State(), Prefix: ones}
+}
+
+// RestoreNetIter returns an iter in the recorded state. It returns an error if the state is not one an iter could be
+// in.
+func RestoreNetIter(s NetIterState) (*NetIter, error) {
+	ips, err := RestoreIPIter(s.IPIterState)
+	if err != nil {
+		return nil, err
+	}
+	if ips.ip == nil {
+		if s.Prefix != 0 {
+			return nil, errors.New("prefix of empty iter")
+		}
+		return new(NetIter), nil
+	}
+
+	bits := 8 * net.IPv6len
+	if s.First.To4() != nil {
+		bits = 8 * net.IPv4len
+	}
+	if s.Prefix < 0 || s.Prefix > bits {
+		return nil, errors.New("invalid prefix length")
+	}
+	mask := net.CIDRMask(s.Prefix, bits)
+	if !s.First.Mask(mask).Equal(s.First) {
+		return nil, errors.New("first network has host bits set")
+	}
+	return &NetIter{ips: *ips, net: &net.IPNet{Mask: mask}}, nil
+}
@@ -0,0 +1,136 @@
+package ipx_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleIPIter_State() {
+	ips := ipx.Addresses(cidr("2001:db8::/48"))
+	ips.Skip(ipx.Uint128{L: 1000})
+
+	b, _ := json.Marshal(ips.State())
+	fmt.Println(string(b))
+
+	var s ipx.IPIterState
+	_ = json.Unmarshal(b, &s)
+	restored, _ := ipx.RestoreIPIter(s)
+	restored.Next()
+	fmt.Println(restored.IP())
+	// Output:
+	// {"First":"2001:db8::","Last":"2001:db8:0:ffff:ffff:ffff:ffff:ffff","Step":"1","Next":"2001:db8::3e8"}
+	// 2001:db8::3e8
+}
+
+func TestIPIterState(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		iter func() *ipx.IPIter
+	}{
+		{"ipv4", func() *ipx.IPIter { return ipx.Addresses(cidr("10.0.0.0/28")) }},
+		{"ipv4 negative", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("10.0.0.20"), -3, net.ParseIP("10.0.0.0")) }},
+		{"ipv6 step", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("2001:db8::"), 5, net.ParseIP("2001:db8::40")) }},
+		{"shard", func() *ipx.IPIter { return ipx.Hosts(cidr("10.0.0.0/27")).Shard(3, 1) }},
+		{"empty", func() *ipx.IPIter { return new(ipx.IPIter) }},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var expected []string
+			for it := c.iter(); it.Next(); {
+				expected = append(expected, it.IP().String())
+			}
+
+			it := c.iter()
+			for i := 0; i <= len(expected); i++ {
+				b, err := json.Marshal(it.State())
+				if err != nil {
+					t.Fatal(err)
+				}
+				var s ipx.IPIterState
+				if err := json.Unmarshal(b, &s); err != nil {
+					t.Fatal(err)
+				}
+				restored, err := ipx.RestoreIPIter(s)
+				if err != nil {
+					t.Fatalf("unexpected error restoring %s: %v", b, err)
+				}
+
+				var got []string
+				for restored.Next() {
+					got = append(got, restored.IP().String())
+				}
+				if fmt.Sprint(got) != fmt.Sprint(expected[i:]) {
+					t.Fatalf("expected %v after restoring %s but got %v", expected[i:], b, got)
+				}
+				restored.Reset()
+				if restored.Len() != it.Len() {
+					t.Fatalf("expected len %v but got %v", it.Len(), restored.Len())
+				}
+				it.Next()
+			}
+		})
+	}
+}
+
+func TestRestoreIPIterInvalid(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		state ipx.IPIterState
+	}{
+		{"missing first", ipx.IPIterState{Last: net.ParseIP("10.0.0.1"), Step: ipx.Uint128{L: 1}}},
+		{"mismatched versions", ipx.IPIterState{First: net.ParseIP("10.0.0.1"), Last: net.ParseIP("::1"), Step: ipx.Uint128{L: 1}}},
+		{"zero step", ipx.IPIterState{First: net.ParseIP("10.0.0.1"), Last: net.ParseIP("10.0.0.3")}},
+		{"ipv4 step too large", ipx.IPIterState{First: net.ParseIP("10.0.0.1"), Last: net.ParseIP("10.0.0.3"), Step: ipx.Uint128{L: 1 << 32}}},
+		{"next off step", ipx.IPIterState{First: net.ParseIP("10.0.0.0"), Last: net.ParseIP("10.0.0.9"), Step: ipx.Uint128{L: 2}, Next: net.ParseIP("10.0.0.3")}},
+		{"next past last", ipx.IPIterState{First: net.ParseIP("10.0.0.0"), Last: net.ParseIP("10.0.0.9"), Step: ipx.Uint128{L: 1}, Next: net.ParseIP("10.0.0.10")}},
+		{"next other version", ipx.IPIterState{First: net.ParseIP("10.0.0.0"), Last: net.ParseIP("10.0.0.9"), Step: ipx.Uint128{L: 1}, Next: net.ParseIP("::1")}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if _, err := ipx.RestoreIPIter(c.state); err == nil {
+				t.Fatal("expected an error")
+			}
+		})
+	}
+}
+
+func TestNetIterState(t *testing.T) {
+	nets := ipx.Split(cidr("2001:db8::/32"), 48)
+	nets.Skip(ipx.Uint128{L: 300})
+
+	b, err := json.Marshal(nets.State())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"First":"2001:db8::","Last":"2001:db8:ffff:ffff:ffff:ffff:ffff:ffff","Step":"1208925819614629174706176","Next":"2001:db8:12c::","Prefix":48}`; string(b) != expected {
+		t.Fatalf("expected %s but got %s", expected, b)
+	}
+	var s ipx.NetIterState
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := ipx.RestoreNetIter(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !restored.Next() || restored.Net().String() != "2001:db8:12c::/48" || restored.Remaining() != (ipx.Uint128{L: 65235}) {
+		t.Fatalf("expected 2001:db8:12c::/48 with 65235 remaining but got %v with %v", restored.Net(), restored.Remaining())
+	}
+
+	if empty, err := ipx.RestoreNetIter(new(ipx.NetIter).State()); err != nil || empty.Next() {
+		t.Fatalf("expected an empty iter but got %v", err)
+	}
+	s.Prefix = 47
+	if _, err := ipx.RestoreNetIter(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s.Prefix = 129
+	if _, err := ipx.RestoreNetIter(s); err == nil {
+		t.Fatal("expected an error for an invalid prefix")
+	}
+	s.Prefix, s.First = 48, net.ParseIP("2001:db8::1")
+	if _, err := ipx.RestoreNetIter(s); err == nil {
+		t.Fatal("expected an error for host bits set")
+	}
+}
@@ -78,7 +78,7 @@ func ExampleIterIP() {
 	// 10.0.0.0
 }
 
-func ExampleIterIP_IP6() {
+func ExampleIterIP_ip6() {
 	ip := net.ParseIP("2001:db8::")
 	for i, iter := 0, ipx.IterIP(ip, 1e18, nil); i < 5 && iter.Next(); i++ {
 		ip = iter.IP()
@@ -162,7 +162,7 @@ func ExampleIterNet() {
 	// 10.0.0.0/16
 }
 
-func ExampleIterNet_IP6() {
+func ExampleIterNet_ip6() {
 	ipN := cidr("2001:db8::/64")
 	for i, iter := 0, ipx.IterNet(ipN, 1e18, nil); i < 5 && iter.Next(); i++ {
 		ipN = iter.Net()
@@ -226,3 +226,291 @@ func BenchmarkIterNet(b *testing.B) {
 		})
 	}
 }
+
+func ExampleIPIter_Shard() {
+	ips := ipx.Addresses(cidr("10.0.0.0/29"))
+	for i := 0; i < 3; i++ {
+		var shard []string
+		for s := ips.Shard(3, i); s.Next(); {
+			shard = append(shard, s.IP().String())
+		}
+		fmt.Println(shard)
+	}
+	// Output:
+	// [10.0.0.0 10.0.0.1 10.0.0.2]
+	// [10.0.0.3 10.0.0.4 10.0.0.5]
+	// [10.0.0.6 10.0.0.7]
+}
+
+func TestIPIterShard(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		iter func() *ipx.IPIter
+	}{
+		{"ipv4 addresses", func() *ipx.IPIter { return ipx.Addresses(cidr("10.0.0.0/28")) }},
+		{"ipv4 hosts", func() *ipx.IPIter { return ipx.Hosts(cidr("10.0.0.0/28")) }},
+		{"ipv4 step", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("10.0.0.0"), 3, net.ParseIP("10.0.0.20")) }},
+		{"ipv4 negative", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("10.0.0.20"), -3, net.ParseIP("10.0.0.0")) }},
+		{"ipv4 top", func() *ipx.IPIter { return ipx.Addresses(cidr("255.255.255.240/28")) }},
+		{"ipv4 single", func() *ipx.IPIter { return ipx.Addresses(cidr("10.0.0.1/32")) }},
+		{"ipv6 addresses", func() *ipx.IPIter { return ipx.Addresses(cidr("2001:db8::/124")) }},
+		{"ipv6 negative", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("2001:db8::20"), -5, net.ParseIP("2001:db8::")) }},
+		{"ipv6 top", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ff00"), 7, nil) }},
+	} {
+		for _, k := range []int{1, 2, 3, 5, 16, 40} {
+			t.Run(fmt.Sprint(c.name, " ", k), func(t *testing.T) {
+				var expected []string
+				for it := c.iter(); it.Next(); {
+					expected = append(expected, it.IP().String())
+				}
+
+				it := c.iter()
+				var got []string
+				min, max := len(expected), 0
+				for i := 0; i < k; i++ {
+					n := 0
+					for s := it.Shard(k, i); s.Next(); n++ {
+						got = append(got, s.IP().String())
+					}
+					if n < min {
+						min = n
+					}
+					if n > max {
+						max = n
+					}
+				}
+				if fmt.Sprint(got) != fmt.Sprint(expected) {
+					t.Fatalf("expected %v but got %v", expected, got)
+				}
+				if max-min > 1 {
+					t.Fatalf("expected shard sizes to differ by at most one but got %v and %v", min, max)
+				}
+			})
+		}
+	}
+}
+
+func TestIPIterShardRemaining(t *testing.T) {
+	it := ipx.Addresses(cidr("10.0.0.0/30"))
+	it.Next()
+	it.Next()
+
+	var got []string
+	for i := 0; i < 2; i++ {
+		for s := it.Shard(2, i); s.Next(); {
+			got = append(got, s.IP().String())
+		}
+	}
+	if expected := "[10.0.0.2 10.0.0.3]"; fmt.Sprint(got) != expected {
+		t.Fatalf("expected %v but got %v", expected, got)
+	}
+	if !it.Next() || it.IP().String() != "10.0.0.2" {
+		t.Fatalf("expected the iter not to advance but got %v", it.IP())
+	}
+}
+
+func TestIPIterShardHuge(t *testing.T) {
+	it := ipx.Addresses(cidr("::/0"))
+	for _, c := range []struct {
+		k, i  int
+		first string
+	}{
+		{1, 0, "::"},
+		{2, 1, "8000::"},
+		{4, 1, "4000::"},
+		{3, 2, "aaaa:aaaa:aaaa:aaaa:aaaa:aaaa:aaaa:aaab"},
+	} {
+		if s := it.Shard(c.k, c.i); !s.Next() || s.IP().String() != c.first {
+			t.Errorf("expected shard %v of %v to start at %v but got %v", c.i, c.k, c.first, s.IP())
+		}
+	}
+
+	// the last shard of the last shard holds the final value
+	s := it.Shard(2, 1)
+	for i := 0; i < 127; i++ {
+		s = s.Shard(2, 1)
+	}
+	if !s.Next() || s.IP().String() != "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff" || s.Next() {
+		t.Errorf("expected only the final address but got %v", s.IP())
+	}
+}
+
+func TestIPIterShardInvalid(t *testing.T) {
+	it := ipx.Addresses(cidr("10.0.0.0/30"))
+	for _, c := range [][2]int{{0, 0}, {-1, 0}, {2, 2}, {2, -1}} {
+		if s := it.Shard(c[0], c[1]); s.Next() {
+			t.Errorf("expected an empty shard for %v but got %v", c, s.IP())
+		}
+	}
+	if s := new(ipx.IPIter).Shard(2, 0); s.Next() {
+		t.Errorf("expected an empty shard of an empty iter but got %v", s.IP())
+	}
+	// more shards than values leaves some empty
+	if s := it.Shard(8, 7); s.Next() {
+		t.Errorf("expected an empty shard but got %v", s.IP())
+	}
+}
+
+func TestNetIterShard(t *testing.T) {
+	var got []string
+	for i := 0; i < 3; i++ {
+		for s := ipx.Split(cidr("10.0.0.0/24"), 27).Shard(3, i); s.Next(); {
+			got = append(got, s.Net().String())
+		}
+	}
+	var expected []string
+	for s := ipx.Split(cidr("10.0.0.0/24"), 27); s.Next(); {
+		expected = append(expected, s.Net().String())
+	}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v but got %v", expected, got)
+	}
+	if s := new(ipx.NetIter).Shard(2, 0); s.Next() {
+		t.Fatalf("expected an empty shard but got %v", s.Net())
+	}
+}
+
+func ExampleIPIter_Seek() {
+	ips := ipx.Addresses(cidr("10.0.0.0/8"))
+	ips.Seek(net.ParseIP("10.200.0.1"))
+	fmt.Println(ips.Peek(), ips.Remaining())
+	ips.Skip(ipx.Uint128{L: 255})
+	fmt.Println(ips.Peek(), ips.Remaining())
+	// Output:
+	// 10.200.0.1 3670015
+	// 10.200.1.0 3669760
+}
+
+func TestIPIterSeek(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		iter     func() *ipx.IPIter
+		seek     string
+		ok       bool
+		expected []string
+	}{
+		{"ipv4 exact", func() *ipx.IPIter { return ipx.Addresses(cidr("10.0.0.0/29")) }, "10.0.0.5", true, []string{"10.0.0.5", "10.0.0.6", "10.0.0.7"}},
+		{"ipv4 before", func() *ipx.IPIter { return ipx.Hosts(cidr("10.0.0.0/29")) }, "9.0.0.0", true, []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"}},
+		{"ipv4 after", func() *ipx.IPIter { return ipx.Addresses(cidr("10.0.0.0/29")) }, "10.0.0.8", false, nil},
+		{"ipv4 between steps", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("10.0.0.0"), 3, net.ParseIP("10.0.0.10")) }, "10.0.0.4", true, []string{"10.0.0.6", "10.0.0.9"}},
+		{"ipv4 negative", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("10.0.0.10"), -3, net.ParseIP("10.0.0.0")) }, "10.0.0.6", true, []string{"10.0.0.4", "10.0.0.1"}},
+		{"ipv4 negative before", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("10.0.0.10"), -3, net.ParseIP("10.0.0.0")) }, "10.0.0.20", true, []string{"10.0.0.10", "10.0.0.7", "10.0.0.4", "10.0.0.1"}},
+		{"ipv4 past last step", func() *ipx.IPIter { return ipx.IterIP(net.ParseIP("10.0.0.0"), 3, net.ParseIP("10.0.0.10")) }, "10.0.0.10", false, nil},
+		{"ipv6", func() *ipx.IPIter { return ipx.Addresses(cidr("2001:db8::/126")) }, "2001:db8::2", true, []string{"2001:db8::2", "2001:db8::3"}},
+		{"ipv6 whole space", func() *ipx.IPIter { return ipx.Addresses(cidr("::/0")) }, "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", true, []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			it := c.iter()
+			// seeking works backwards too, so start from the end
+			it.Skip(ipx.Uint128{H: 1<<64 - 1, L: 1<<64 - 1})
+			it.Next()
+			if ok := it.Seek(net.ParseIP(c.seek)); ok != c.ok {
+				t.Fatalf("expected %v but got %v", c.ok, ok)
+			}
+			var got []string
+			for it.Next() {
+				got = append(got, it.IP().String())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(c.expected) {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+
+	it := ipx.Addresses(cidr("10.0.0.0/30"))
+	if it.Seek(net.ParseIP("2001:db8::")) || !it.Next() || it.IP().String() != "10.0.0.0" {
+		t.Fatalf("expected seeking another IP version to leave the iter unchanged")
+	}
+}
+
+func TestIPIterLen(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		iter      *ipx.IPIter
+		len       ipx.Uint128
+		remaining []uint64
+	}{
+		{"ipv4", ipx.Addresses(cidr("10.0.0.0/30")), ipx.Uint128{L: 4}, []uint64{4, 3, 2, 1, 0}},
+		{"ipv4 step", ipx.IterIP(net.ParseIP("10.0.0.0"), 3, net.ParseIP("10.0.0.8")), ipx.Uint128{L: 3}, []uint64{3, 2, 1, 0}},
+		{"ipv4 negative", ipx.IterIP(net.ParseIP("10.0.0.9"), -4, net.ParseIP("10.0.0.0")), ipx.Uint128{L: 3}, []uint64{3, 2, 1, 0}},
+		{"ipv4 whole space", ipx.Addresses(cidr("0.0.0.0/0")), ipx.Uint128{L: 1 << 32}, []uint64{1 << 32, 1<<32 - 1}},
+		{"ipv6", ipx.Hosts(cidr("2001:db8::/126")), ipx.Uint128{L: 3}, []uint64{3, 2, 1, 0}},
+		{"ipv6 whole space", ipx.Addresses(cidr("::/0")), ipx.Uint128{H: 1<<64 - 1, L: 1<<64 - 1}, nil},
+		{"empty", new(ipx.IPIter), ipx.Uint128{}, []uint64{0}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if l := c.iter.Len(); l != c.len {
+				t.Fatalf("expected len %v but got %v", c.len, l)
+			}
+			for _, r := range c.remaining {
+				if got := c.iter.Remaining(); got != (ipx.Uint128{L: r}) {
+					t.Fatalf("expected %v remaining but got %v", r, got)
+				}
+				c.iter.Next()
+			}
+			if l := c.iter.Len(); l != c.len {
+				t.Fatalf("expected len %v after iterating but got %v", c.len, l)
+			}
+		})
+	}
+}
+
+func TestIPIterSkipReset(t *testing.T) {
+	it := ipx.IterIP(net.ParseIP("2001:db8::"), 2, net.ParseIP("2001:db8::10"))
+	if !it.Skip(ipx.Uint128{L: 3}) || !it.Next() || it.IP().String() != "2001:db8::6" {
+		t.Fatalf("expected to skip to 2001:db8::6 but got %v", it.IP())
+	}
+	if p := it.Peek(); p.String() != "2001:db8::8" || it.IP().String() != "2001:db8::6" {
+		t.Fatalf("expected to peek 2001:db8::8 without advancing but got %v and %v", p, it.IP())
+	}
+	if it.Skip(ipx.Uint128{L: 4}) || it.Next() || it.Peek() != nil {
+		t.Fatalf("expected skipping past the end to exhaust the iter")
+	}
+	if it.Skip(ipx.Uint128{H: 1}) {
+		t.Fatalf("expected skipping an exhausted iter to fail")
+	}
+
+	it.Reset()
+	var got []string
+	for it.Next() {
+		got = append(got, it.IP().String())
+	}
+	if expected := "[2001:db8:: 2001:db8::2 2001:db8::4 2001:db8::6 2001:db8::8 2001:db8::a 2001:db8::c 2001:db8::e]"; fmt.Sprint(got) != expected {
+		t.Fatalf("expected %v after reset but got %v", expected, got)
+	}
+
+	all := ipx.Addresses(cidr("::/0"))
+	if all.Skip(ipx.Uint128{H: 1<<64 - 1, L: 1<<64 - 1}) != true || !all.Next() || all.Next() {
+		t.Fatalf("expected one address to remain")
+	}
+
+	empty := new(ipx.IPIter)
+	empty.Reset()
+	if empty.Next() || empty.Peek() != nil || empty.Seek(net.ParseIP("10.0.0.0")) {
+		t.Fatalf("expected an empty iter to remain empty")
+	}
+}
+
+func TestNetIterSeek(t *testing.T) {
+	nets := ipx.Split(cidr("10.0.0.0/16"), 24)
+	if !nets.Seek(net.ParseIP("10.0.200.7")) {
+		t.Fatal("expected to seek")
+	}
+	if p := nets.Peek(); p.String() != "10.0.200.0/24" {
+		t.Fatalf("expected to peek 10.0.200.0/24 but got %v", p)
+	}
+	if r := nets.Remaining(); r != (ipx.Uint128{L: 56}) || nets.Len() != (ipx.Uint128{L: 256}) {
+		t.Fatalf("expected 56 of 256 remaining but got %v of %v", r, nets.Len())
+	}
+	nets.Skip(ipx.Uint128{L: 55})
+	if !nets.Next() || nets.Net().String() != "10.0.255.0/24" || nets.Next() {
+		t.Fatalf("expected only 10.0.255.0/24 to remain but got %v", nets.Net())
+	}
+	nets.Reset()
+	if !nets.Next() || nets.Net().String() != "10.0.0.0/24" {
+		t.Fatalf("expected to reset to 10.0.0.0/24 but got %v", nets.Net())
+	}
+	if new(ipx.NetIter).Seek(net.ParseIP("10.0.0.0")) || new(ipx.NetIter).Peek() != nil {
+		t.Fatal("expected an empty iter to remain empty")
+	}
+}
@@ -0,0 +1,272 @@
+package ipx
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// MaskedAddr is an address together with an arbitrary mask, which unlike a net.IPMask need not be contiguous. An
+// address matches when it equals the MaskedAddr's address in every bit set in the mask. This is how Cisco and Juniper
+// ACLs match using wildcard masks such as 10.0.0.0 0.255.0.255, where the mask is the inverse of the wildcard.
+type MaskedAddr struct {
+	addr, mask Uint128 // IPv4 values occupy the low 32 bits
+	four       bool
+}
+
+// NewMaskedAddr returns a MaskedAddr for the address and mask. Bits of ip not set in the mask are cleared. It returns an
+// error if the mask's length doesn't match the address's IP version.
+func NewMaskedAddr(ip net.IP, mask net.IPMask) (MaskedAddr, error) {
+	ip, bits := ipAndBits(ip)
+	switch {
+	case ip == nil:
+		return MaskedAddr{}, errors.New("invalid IP address")
+	case len(mask) == net.IPv6len && bits == 8*net.IPv4len && allFF(mask[:12]):
+		mask = mask[12:]
+	case len(mask)*8 != bits:
+		return MaskedAddr{}, errors.New("mask length doesn't match IP version")
+	}
+
+	m := MaskedAddr{four: bits == 8*net.IPv4len}
+	if m.four {
+		m.addr, m.mask = Uint128{0, uint64(to32(ip))}, Uint128{0, uint64(to32(mask))}
+	} else {
+		m.addr, m.mask = To128(ip), To128(mask)
+	}
+	m.addr = m.addr.And(m.mask)
+	return m, nil
+}
+
+// MaskedAddrFromNet returns the MaskedAddr matching the same addresses as ipNet.
+func MaskedAddrFromNet(ipNet *net.IPNet) (MaskedAddr, error) {
+	return NewMaskedAddr(ipNet.IP, ipNet.Mask)
+}
+
+// ParseMaskedAddr parses s as an address followed by either a slash and a prefix length or mask, e.g. 10.0.0.0/8 or
+// 10.0.0.0/255.0.255.0, or whitespace and a wildcard mask, e.g. 10.0.0.0 0.255.0.255, as in Cisco ACLs. A lone
+// address matches only itself.
+func ParseMaskedAddr(s string) (MaskedAddr, error) {
+	var (
+		addr, mask string
+		wildcard   bool
+	)
+	switch fields := strings.Fields(s); {
+	case strings.Contains(s, "/"):
+		i := strings.IndexByte(s, '/')
+		addr, mask = s[:i], s[i+1:]
+	case len(fields) == 2:
+		addr, mask, wildcard = fields[0], fields[1], true
+	case len(fields) == 1:
+		addr = fields[0]
+	default:
+		return MaskedAddr{}, &net.ParseError{Type: "masked address", Text: s}
+	}
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return MaskedAddr{}, &net.ParseError{Type: "masked address", Text: s}
+	}
+	ip, bits := ipAndBits(ip)
+
+	var m net.IPMask
+	switch {
+	case mask == "":
+		m = net.CIDRMask(bits, bits)
+	case !strings.ContainsAny(mask, ".:"):
+		ones, err := strconv.ParseUint(mask, 10, 8)
+		if err != nil || int(ones) > bits {
+			return MaskedAddr{}, &net.ParseError{Type: "masked address", Text: s}
+		}
+		m = net.CIDRMask(int(ones), bits)
+	default:
+		mIP := net.ParseIP(mask)
+		if mIP == nil || (bits == 8*net.IPv6len) != strings.Contains(mask, ":") {
+			return MaskedAddr{}, &net.ParseError{Type: "masked address", Text: s}
+		}
+		m = net.IPMask(mIP)
+		if bits == 8*net.IPv4len {
+			m = net.IPMask(mIP.To4())
+		}
+		if wildcard {
+			for i := range m {
+				m[i] = ^m[i]
+			}
+		}
+	}
+	return NewMaskedAddr(ip, m)
+}
+
+// IP returns the address, with the bits not set in the mask cleared.
+func (m MaskedAddr) IP() net.IP {
+	return m.toIP(m.addr)
+}
+
+// Mask returns the mask.
+func (m MaskedAddr) Mask() net.IPMask {
+	return net.IPMask(m.toIP(m.mask))
+}
+
+// Wildcard returns the inverse of the mask, as written in Cisco ACLs.
+func (m MaskedAddr) Wildcard() net.IPMask {
+	return net.IPMask(m.toIP(m.mask.Not()))
+}
+
+func (m MaskedAddr) toIP(u Uint128) net.IP {
+	if m.four {
+		ip := make(net.IP, net.IPv4len)
+		from32(uint32(u.L), ip)
+		return ip
+	}
+	ip := make(net.IP, net.IPv6len)
+	From128(u, ip)
+	return ip
+}
+
+// bits returns the number of bits in addresses of the MaskedAddr's IP version.
+func (m MaskedAddr) bits() int {
+	if m.four {
+		return 8 * net.IPv4len
+	}
+	return 8 * net.IPv6len
+}
+
+// free returns the bits which may take any value.
+func (m MaskedAddr) free() Uint128 {
+	f := m.mask.Not()
+	if m.four {
+		f = f.And(Uint128{0, maxUint32})
+	}
+	return f
+}
+
+// String returns the address and mask separated by a slash, e.g. 10.0.0.0/255.0.255.0.
+func (m MaskedAddr) String() string {
+	return m.IP().String() + "/" + net.IP(m.Mask()).String()
+}
+
+// Contains returns whether ip matches.
+func (m MaskedAddr) Contains(ip net.IP) bool {
+	u, err := IPToUint128(ip)
+	if err != nil || (ip.To4() != nil) != m.four {
+		return false
+	}
+	return u.Xor(m.addr).And(m.mask).IsZero()
+}
+
+// Intersect returns the MaskedAddr matching exactly the addresses matched by both m and o, and false if there are no
+// such addresses.
+func (m MaskedAddr) Intersect(o MaskedAddr) (MaskedAddr, bool) {
+	if m.four != o.four || !m.addr.Xor(o.addr).And(m.mask).And(o.mask).IsZero() {
+		return MaskedAddr{}, false
+	}
+	return MaskedAddr{addr: m.addr.Or(o.addr), mask: m.mask.Or(o.mask), four: m.four}, true
+}
+
+// IsContiguous returns whether the mask is contiguous, i.e. whether m matches a single network.
+func (m MaskedAddr) IsContiguous() bool {
+	free := m.free()
+	// contiguous free bits are all trailing, so adding one carries through all of them
+	return free.And(free.Add(Uint128{0, 1})).IsZero()
+}
+
+// Size returns the number of addresses matched.
+func (m MaskedAddr) Size() Uint128 {
+	n := m.free().OnesCount()
+	if n == 128 {
+		return Uint128{maxUint64, maxUint64}
+	}
+	return Uint128{0, 1}.Lsh(uint(n))
+}
+
+// Nets returns the minimal list of networks matching the same addresses as m, in ascending order. Each free bit above
+// the lowest bit set in the mask doubles the number of networks, so if more than limit networks would be required,
+// nil and false are returned.
+func (m MaskedAddr) Nets(limit int) ([]*net.IPNet, bool) {
+	free := m.free()
+	hostBits := m.mask.TrailingZeros()
+	if hostBits > m.bits() {
+		hostBits = m.bits()
+	}
+	// the free bits above the host bits must be enumerated, each combination giving a network
+	high := free.Rsh(uint(hostBits)).Lsh(uint(hostBits))
+	n := high.OnesCount()
+	if n >= 63 || 1<<uint(n) > limit {
+		return nil, false
+	}
+
+	nets := make([]*net.IPNet, 0, 1<<uint(n))
+	mask := net.CIDRMask(m.bits()-hostBits, m.bits())
+	iter := newSubsetIter(m.addr, high)
+	for iter.next() {
+		nets = append(nets, &net.IPNet{IP: m.toIP(iter.val()), Mask: mask})
+	}
+	return nets, true
+}
+
+// Addresses returns all of the matched addresses in ascending order.
+func (m MaskedAddr) Addresses() *MaskedAddrIter {
+	iter := &MaskedAddrIter{subsets: newSubsetIter(m.addr, m.free())}
+	if m.four {
+		iter.ip = make(net.IP, net.IPv4len)
+	} else {
+		iter.ip = make(net.IP, net.IPv6len)
+	}
+	return iter
+}
+
+// MaskedAddrIter permits iteration over the addresses matched by a MaskedAddr.
+type MaskedAddrIter struct {
+	subsets subsetIter
+	ip      net.IP
+}
+
+// IP returns the most recent IP; the underlying type may be modified on later calls to `Next`.
+// It does no allocation.
+func (i *MaskedAddrIter) IP() net.IP {
+	return i.ip
+}
+
+// Next returns true when the underlying pointer has been successfully updated with the next value.
+func (i *MaskedAddrIter) Next() bool {
+	if !i.subsets.next() {
+		return false
+	}
+	if len(i.ip) == net.IPv4len {
+		from32(uint32(i.subsets.val().L), i.ip)
+	} else {
+		From128(i.subsets.val(), i.ip)
+	}
+	return true
+}
+
+// subsetIter enumerates base combined with every subset of the bits of free, in ascending order.
+type subsetIter struct {
+	base, free, cur  Uint128
+	started, stopped bool
+}
+
+func newSubsetIter(base, free Uint128) subsetIter {
+	return subsetIter{base: base.AndNot(free), free: free}
+}
+
+func (s *subsetIter) next() bool {
+	switch {
+	case s.stopped:
+		return false
+	case !s.started:
+		s.started = true
+		return true
+	}
+	// setting every bit outside of free before incrementing carries the increment to the next free bit
+	s.cur = s.cur.Or(s.free.Not()).Add(Uint128{0, 1}).And(s.free)
+	if s.cur.IsZero() {
+		s.stopped = true
+		return false
+	}
+	return true
+}
+
+func (s *subsetIter) val() Uint128 {
+	return s.base.Or(s.cur)
+}
@@ -0,0 +1,165 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleMaskedAddr() {
+	m, _ := ipx.ParseMaskedAddr("10.0.0.0 0.255.0.255")
+	fmt.Println(m, m.Contains(net.ParseIP("10.20.0.9")), m.Size())
+	nets, _ := m.Nets(1024)
+	fmt.Println(len(nets), nets[0], nets[len(nets)-1])
+	// Output:
+	// 10.0.0.0/255.0.255.0 true 65536
+	// 256 10.0.0.0/24 10.255.0.0/24
+}
+
+func TestParseMaskedAddr(t *testing.T) {
+	for _, c := range []struct {
+		in, expected string
+	}{
+		{"10.0.0.0 0.255.0.255", "10.0.0.0/255.0.255.0"},
+		{"10.1.2.3 0.255.0.255", "10.0.2.0/255.0.255.0"},
+		{"10.0.0.0/255.0.255.0", "10.0.0.0/255.0.255.0"},
+		{"10.0.0.0/8", "10.0.0.0/255.0.0.0"},
+		{"10.0.0.1", "10.0.0.1/255.255.255.255"},
+		{"2001:db8::1 ffff::ffff", "0:db8::/0:ffff:ffff:ffff:ffff:ffff:ffff:0"},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			m, err := ipx.ParseMaskedAddr(c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if m.String() != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, m)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "10.0.0.0/33", "10.0.0.0 ffff::", "2001:db8:: 0.0.0.255", "a b c", "10.0.0.0/x"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			if m, err := ipx.ParseMaskedAddr(in); err == nil {
+				t.Fatalf("expected error but got %v", m)
+			}
+		})
+	}
+}
+
+func TestMaskedAddrContains(t *testing.T) {
+	m, _ := ipx.ParseMaskedAddr("10.0.0.1 0.255.0.0")
+	for _, c := range []struct {
+		ip       string
+		expected bool
+	}{
+		{"10.0.0.1", true},
+		{"10.99.0.1", true},
+		{"10.99.1.1", false},
+		{"11.0.0.1", false},
+		{"::ffff:10.1.0.1", true},
+		{"2001:db8::1", false},
+	} {
+		if got := m.Contains(net.ParseIP(c.ip)); got != c.expected {
+			t.Errorf("expected %v for %v but got %v", c.expected, c.ip, got)
+		}
+	}
+}
+
+func TestMaskedAddrIntersect(t *testing.T) {
+	for _, c := range []struct {
+		a, b, expected string
+	}{
+		{"10.0.0.0 0.255.0.255", "10.1.0.0 0.0.255.255", "10.1.0.0/255.255.255.0"},
+		{"10.0.0.0 0.255.0.255", "10.1.1.0 0.0.0.255", "<nil>"},
+		{"10.0.0.0/8", "10.1.0.0/16", "10.1.0.0/255.255.0.0"},
+		{"10.0.0.0/8", "2001:db8::/32", "<nil>"},
+	} {
+		t.Run(c.a+" "+c.b, func(t *testing.T) {
+			a, _ := ipx.ParseMaskedAddr(c.a)
+			b, _ := ipx.ParseMaskedAddr(c.b)
+			var got interface{} = "<nil>"
+			if i, ok := a.Intersect(b); ok {
+				got = i
+			}
+			if fmt.Sprint(got) != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestMaskedAddrNets(t *testing.T) {
+	for _, c := range []struct {
+		in         string
+		limit      int
+		expected   []string
+		contiguous bool
+	}{
+		{"10.0.0.0/8", 1, []string{"10.0.0.0/8"}, true},
+		{"10.0.0.0 0.1.0.255", 2, []string{"10.0.0.0/24", "10.1.0.0/24"}, false},
+		{"10.0.0.0 0.1.0.255", 1, nil, false},
+		{"10.0.0.0 1.0.0.0", 4, []string{"10.0.0.0/32", "11.0.0.0/32"}, false},
+		{"0.0.0.0 255.255.255.255", 1, []string{"0.0.0.0/0"}, true},
+		{"2001:db8::/32", 1, []string{"2001:db8::/32"}, true},
+		{"2001:db8:: 0:0:0:1::ffff", 2, []string{"2001:db8::/112", "2001:db8:0:1::/112"}, false},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			m, err := ipx.ParseMaskedAddr(c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if m.IsContiguous() != c.contiguous {
+				t.Fatalf("expected contiguous to be %v", c.contiguous)
+			}
+			nets, ok := m.Nets(c.limit)
+			if ok != (c.expected != nil) {
+				t.Fatalf("expected ok to be %v", c.expected != nil)
+			}
+			var got []string
+			for _, n := range nets {
+				got = append(got, n.String())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(c.expected) {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestMaskedAddrAddresses(t *testing.T) {
+	for _, c := range []struct {
+		in       string
+		expected []string
+	}{
+		{"10.0.0.0 0.0.1.1", []string{"10.0.0.0", "10.0.0.1", "10.0.1.0", "10.0.1.1"}},
+		{"10.0.0.5", []string{"10.0.0.5"}},
+		{"::1 8000::", []string{"::1", "8000::1"}},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			m, _ := ipx.ParseMaskedAddr(c.in)
+			var got []string
+			for iter := m.Addresses(); iter.Next(); {
+				got = append(got, iter.IP().String())
+			}
+			if fmt.Sprint(got) != fmt.Sprint(c.expected) {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+			if m.Size() != (ipx.Uint128{L: uint64(len(got))}) {
+				t.Fatalf("expected size %v but got %v", len(got), m.Size())
+			}
+		})
+	}
+
+	// every address of a /0 must be visited, including the last
+	m, _ := ipx.ParseMaskedAddr("255.255.255.0 0.0.0.255")
+	count := 0
+	var last string
+	for iter := m.Addresses(); iter.Next(); count++ {
+		last = iter.IP().String()
+	}
+	if count != 256 || last != "255.255.255.255" {
+		t.Fatalf("expected 256 addresses ending with 255.255.255.255 but got %v ending with %v", count, last)
+	}
+}
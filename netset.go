@@ -0,0 +1,96 @@
+package ipx
+
+import (
+	"net"
+	"sort"
+)
+
+// NetSet is an immutable set of addresses compiled from networks for fast membership tests. Lookups take time
+// logarithmic in the number of disjoint ranges in the set and do no allocation.
+type NetSet struct {
+	four, six []span // sorted, disjoint and not adjacent
+}
+
+// NewNetSet returns the set of addresses in the networks. Host bits set in any of the networks are ignored, as are
+// networks with invalid masks.
+func NewNetSet(nets []*net.IPNet) *NetSet {
+	s := new(NetSet)
+	for _, ipNet := range nets {
+		sp, four, ok := netSpan(ipNet)
+		switch {
+		case !ok:
+		case four:
+			s.four = append(s.four, sp)
+		default:
+			s.six = append(s.six, sp)
+		}
+	}
+	s.four, s.six = mergeSpans(s.four), mergeSpans(s.six)
+	return s
+}
+
+// netSpan returns the network's addresses as integers and whether they are IPv4.
+func netSpan(ipNet *net.IPNet) (s span, four bool, ok bool) {
+	n := Canonicalize(ipNet)
+	if n == nil {
+		return span{}, false, false
+	}
+	ones, bits := n.Mask.Size()
+	s.first, _ = IPToUint128(n.IP)
+	if bits-ones == 128 {
+		s.last = Uint128{maxUint64, maxUint64}
+	} else {
+		s.last = s.first.Add(Uint128{0, 1}.Lsh(uint(bits - ones)).Minus(Uint128{0, 1}))
+	}
+	return s, bits == 8*net.IPv4len, true
+}
+
+// mergeSpans sorts the spans and combines those which overlap or are adjacent.
+func mergeSpans(spans []span) []span {
+	if len(spans) == 0 {
+		return nil
+	}
+	sort.Slice(spans, func(i, j int) bool {
+		return spans[i].first.Cmp(spans[j].first) == -1
+	})
+	merged := spans[:1]
+	for _, s := range spans[1:] {
+		last := &merged[len(merged)-1]
+		if next, overflow := last.last.AddOverflow(Uint128{0, 1}); !overflow && s.first.Cmp(next) == 1 {
+			merged = append(merged, s)
+			continue
+		}
+		if s.last.Cmp(last.last) == 1 {
+			last.last = s.last
+		}
+	}
+	return merged
+}
+
+// Contains returns whether ip is in the set.
+func (s *NetSet) Contains(ip net.IP) bool {
+	u, err := IPToUint128(ip)
+	if err != nil {
+		return false
+	}
+	spans := s.six
+	if ip.To4() != nil {
+		spans = s.four
+	}
+	i := sort.Search(len(spans), func(i int) bool {
+		return spans[i].last.Cmp(u) != -1
+	})
+	return i < len(spans) && spans[i].first.Cmp(u) != 1
+}
+
+// Nets returns the minimal list of networks covering the set, IPv4 networks first and each version in ascending order.
+func (s *NetSet) Nets() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, sp := range s.four {
+		nets = append(nets, summarizeRange4(uint32(sp.first.L), uint32(sp.last.L))...)
+	}
+	for _, sp := range s.six {
+		nets = append(nets, summarizeRange6(sp.first, sp.last)...)
+	}
+	return nets
+}
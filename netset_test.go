@@ -0,0 +1,88 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"math/rand"
+	"net"
+	"testing"
+)
+
+func ExampleNetSet() {
+	s := ipx.NewNetSet([]*net.IPNet{cidr("10.0.0.0/25"), cidr("10.0.0.128/25"), cidr("2001:db8::/32")})
+	fmt.Println(s.Contains(net.ParseIP("10.0.0.200")), s.Contains(net.ParseIP("10.0.1.0")), s.Nets())
+	// Output:
+	// true false [10.0.0.0/24 2001:db8::/32]
+}
+
+func TestNetSet(t *testing.T) {
+	s := ipx.NewNetSet([]*net.IPNet{
+		cidr("10.0.0.0/8"),
+		cidr("10.1.0.0/16"),
+		cidr("192.0.2.4/30"),
+		cidr("192.0.2.8/31"),
+		cidr("2001:db8::/32"),
+		cidr("ffff::/16"),
+		{IP: net.ParseIP("10.0.0.0"), Mask: net.IPv4Mask(255, 0, 255, 0)},
+	})
+	for _, c := range []struct {
+		ip       string
+		expected bool
+	}{
+		{"9.255.255.255", false},
+		{"10.0.0.0", true},
+		{"10.255.255.255", true},
+		{"11.0.0.0", false},
+		{"192.0.2.3", false},
+		{"192.0.2.4", true},
+		{"192.0.2.9", true},
+		{"192.0.2.10", false},
+		{"::ffff:10.1.2.3", true},
+		{"2001:db8:ffff::1", true},
+		{"2001:db9::", false},
+		{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", true},
+		{"::a00:1", false},
+	} {
+		if got := s.Contains(net.ParseIP(c.ip)); got != c.expected {
+			t.Errorf("expected %v for %v but got %v", c.expected, c.ip, got)
+		}
+	}
+	if got, expected := fmt.Sprint(s.Nets()), "[10.0.0.0/8 192.0.2.4/30 192.0.2.8/31 2001:db8::/32 ffff::/16]"; got != expected {
+		t.Errorf("expected %v but got %v", expected, got)
+	}
+
+	all := ipx.NewNetSet([]*net.IPNet{cidr("0.0.0.0/0"), cidr("::/0")})
+	if !all.Contains(net.ParseIP("255.255.255.255")) || !all.Contains(net.ParseIP("::")) {
+		t.Errorf("expected full set to contain everything")
+	}
+}
+
+func TestNetSetMatchesIsSubnet(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	var nets []*net.IPNet
+	for i := 0; i < 100; i++ {
+		ip := make(net.IP, 4)
+		r.Read(ip)
+		nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(8+r.Intn(25), 32)})
+	}
+	s := ipx.NewNetSet(nets)
+	for i := 0; i < 10000; i++ {
+		ip := make(net.IP, 4)
+		r.Read(ip)
+		if i%2 == 0 { // land near a network to exercise the boundaries
+			n := nets[r.Intn(len(nets))]
+			copy(ip, n.IP.Mask(n.Mask))
+			ipx.IncrIP(ip, r.Intn(3)-1)
+		}
+
+		var expected bool
+		for _, n := range nets {
+			if n.Contains(ip) {
+				expected = true
+			}
+		}
+		if got := s.Contains(ip); got != expected {
+			t.Fatalf("expected %v for %v but got %v", expected, ip, got)
+		}
+	}
+}
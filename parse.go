@@ -0,0 +1,189 @@
+package ipx
+
+import (
+	"net"
+	"strconv"
+	"strings"
+)
+
+// IPNotation is a set of flags describing how the text of an address was written. The zero value is a canonical
+// dotted-decimal IPv4 address.
+type IPNotation uint8
+
+const (
+	// NotationIPv6 is set when the address was written as IPv6.
+	NotationIPv6 IPNotation = 1 << iota
+	// NotationV4Mapped is set when an IPv4 address was written as a v4-mapped IPv6 address, e.g. ::ffff:192.0.2.1.
+	NotationV4Mapped
+	// NotationZone is set when an IPv6 address carried a zone, e.g. fe80::1%eth0.
+	NotationZone
+	// NotationShort is set when an IPv4 address had two or three parts, e.g. 10.1, with the last part filling the
+	// remaining bytes.
+	NotationShort
+	// NotationInteger is set when an IPv4 address was written as a single 32 bit number, e.g. 167772161.
+	NotationInteger
+	// NotationHex is set when any IPv4 part was written in hexadecimal, e.g. 0x0a.0.0.1.
+	NotationHex
+	// NotationOctal is set when any IPv4 part was written in octal, i.e. had a leading zero, e.g. 012.0.0.1.
+	NotationOctal
+)
+
+var notationNames = []string{"ipv6", "v4-mapped", "zone", "short", "integer", "hex", "octal"}
+
+// String returns the names of the set flags separated by '|', or "canonical" when none are set.
+func (n IPNotation) String() string {
+	if n == 0 {
+		return "canonical"
+	}
+	var names []string
+	for i, name := range notationNames {
+		if n&(1<<uint(i)) != 0 {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, "|")
+}
+
+// ParsedIP is the result of parsing the text of an IP address.
+type ParsedIP struct {
+	IP       net.IP
+	Zone     string
+	Notation IPNotation
+}
+
+// ParseIPLenient parses s as an IP address, accepting everything the C library's inet_aton accepts for IPv4 -- one
+// to four parts, each decimal, octal with a leading zero or hexadecimal with a leading 0x, the last part filling the
+// remaining bytes -- and IPv6 addresses with an optional zone. The returned notation reports which of these forms
+// was used. IPv4 addresses are returned in their 16 byte form, like net.ParseIP.
+func ParseIPLenient(s string) (ParsedIP, error) {
+	if strings.Contains(s, ":") {
+		return parseIP6(s, false)
+	}
+	n, notation, ok := parseInetAton(s)
+	if !ok {
+		return ParsedIP{}, &net.ParseError{Type: "IP address", Text: s}
+	}
+	return ParsedIP{IP: Uint32ToIP(n), Notation: notation}, nil
+}
+
+// ParseIPStrict parses s as an IP address, rejecting any form whose meaning differs between parsers: IPv4 addresses
+// must be exactly four decimal parts without leading zeros, and IPv6 addresses may not carry a zone or embed such an
+// IPv4 address. The returned notation reports whether the address was written as IPv6 or v4-mapped IPv6.
+func ParseIPStrict(s string) (ParsedIP, error) {
+	if strings.Contains(s, ":") {
+		return parseIP6(s, true)
+	}
+	n, ok := parseDottedDecimal(s)
+	if !ok {
+		return ParsedIP{}, &net.ParseError{Type: "IP address", Text: s}
+	}
+	return ParsedIP{IP: Uint32ToIP(n)}, nil
+}
+
+func parseIP6(s string, strict bool) (ParsedIP, error) {
+	p := ParsedIP{Notation: NotationIPv6}
+
+	addr := s
+	if i := strings.IndexByte(s, '%'); i >= 0 {
+		if strict || i == len(s)-1 {
+			return ParsedIP{}, &net.ParseError{Type: "IP address", Text: s}
+		}
+		addr, p.Zone = s[:i], s[i+1:]
+		p.Notation |= NotationZone
+	}
+
+	if i := strings.LastIndexByte(addr, ':'); strict && strings.Contains(addr[i+1:], ".") {
+		if _, ok := parseDottedDecimal(addr[i+1:]); !ok {
+			return ParsedIP{}, &net.ParseError{Type: "IP address", Text: s}
+		}
+	}
+
+	if p.IP = net.ParseIP(addr); p.IP == nil {
+		return ParsedIP{}, &net.ParseError{Type: "IP address", Text: s}
+	}
+	if p.IP.To4() != nil {
+		p.Notation |= NotationV4Mapped
+	}
+	return p, nil
+}
+
+// parseDottedDecimal parses exactly four decimal parts in [0, 255] without leading zeros.
+func parseDottedDecimal(s string) (uint32, bool) {
+	var (
+		n     uint32
+		parts int
+	)
+	for _, part := range strings.Split(s, ".") {
+		if part == "" || len(part) > 3 || (len(part) > 1 && part[0] == '0') {
+			return 0, false
+		}
+		v, err := strconv.ParseUint(part, 10, 8)
+		if err != nil {
+			return 0, false
+		}
+		n = n<<8 | uint32(v)
+		parts++
+	}
+	return n, parts == 4
+}
+
+// parseInetAton parses the one to four part forms accepted by inet_aton(3).
+func parseInetAton(s string) (uint32, IPNotation, bool) {
+	parts := strings.Split(s, ".")
+	if len(parts) > 4 {
+		return 0, 0, false
+	}
+
+	var (
+		n        uint32
+		notation IPNotation
+	)
+	last := len(parts) - 1
+	for i, part := range parts {
+		v, partNotation, ok := parseInetAtonPart(part)
+		if !ok {
+			return 0, 0, false
+		}
+		notation |= partNotation
+
+		if i < last {
+			// all but the last part are a single byte
+			if v > 0xff {
+				return 0, 0, false
+			}
+			n |= uint32(v) << uint(24-8*i)
+			continue
+		}
+		// the last part fills all of the remaining bytes
+		if v > maxUint32>>uint(8*last) {
+			return 0, 0, false
+		}
+		n |= uint32(v)
+	}
+
+	switch len(parts) {
+	case 1:
+		notation |= NotationInteger
+	case 2, 3:
+		notation |= NotationShort
+	}
+	return n, notation, true
+}
+
+func parseInetAtonPart(part string) (uint64, IPNotation, bool) {
+	var (
+		base     = 10
+		notation IPNotation
+	)
+	switch {
+	case len(part) > 2 && part[0] == '0' && lowerASCII(part[1]) == 'x':
+		base, part, notation = 16, part[2:], NotationHex
+	case len(part) > 1 && part[0] == '0':
+		base, part, notation = 8, part[1:], NotationOctal
+	}
+	v, err := strconv.ParseUint(part, base, 32)
+	if err != nil {
+		return 0, 0, false
+	}
+	return v, notation, true
+}
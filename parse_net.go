@@ -0,0 +1,161 @@
+package ipx
+
+import (
+	"errors"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// ParseNetStrict parses s as a network in CIDR notation, e.g. 192.0.2.0/24. Unlike net.ParseCIDR, it returns an error
+// rather than masking when host bits are set, and the address must satisfy ParseIPStrict. The prefix length must be
+// decimal without leading zeros.
+func ParseNetStrict(s string) (*net.IPNet, error) {
+	i := strings.IndexByte(s, '/')
+	if i < 0 {
+		return nil, &net.ParseError{Type: "CIDR address", Text: s}
+	}
+	addr, prefix := s[:i], s[i+1:]
+
+	p, err := ParseIPStrict(addr)
+	if err != nil || (len(prefix) > 1 && prefix[0] == '0') {
+		return nil, &net.ParseError{Type: "CIDR address", Text: s}
+	}
+
+	ip, bits := writtenIPAndBits(addr, p.IP)
+	ones, err := strconv.ParseUint(prefix, 10, 8)
+	if err != nil || int(ones) > bits {
+		return nil, &net.ParseError{Type: "CIDR address", Text: s}
+	}
+
+	ipNet := &net.IPNet{}
+	ipNet.IP, ipNet.Mask = shortenMapped(ip, net.CIDRMask(int(ones), bits))
+	if !ipNet.IP.Equal(ipNet.IP.Mask(ipNet.Mask)) {
+		return nil, errors.New("host bits set in " + s)
+	}
+	return ipNet, nil
+}
+
+// ParseNetLenient parses s as a network, returning the address as written alongside the network with any host bits
+// cleared, like net.ParseCIDR. The address may be in any form accepted by ParseIPLenient, but without a zone. The mask
+// may be a prefix length (10.0.0.0/24), a netmask (10.0.0.0/255.255.255.0), a whitespace separated netmask or Cisco
+// wildcard mask (10.0.0.0 255.255.255.0 or 10.0.0.0 0.0.0.255), or omitted for a single address (10.0.0.1 is
+// 10.0.0.1/32). A whitespace separated mask is read as a netmask when its first bit is set and as a wildcard mask
+// otherwise, so both 0.0.0.0 and 255.255.255.255 denote a single address. Masks must be contiguous.
+func ParseNetLenient(s string) (net.IP, *net.IPNet, error) {
+	var (
+		addr, mask     string
+		wildcard, bare bool
+	)
+	switch fields := strings.Fields(s); {
+	case strings.Contains(s, "/"):
+		i := strings.IndexByte(s, '/')
+		addr, mask = s[:i], s[i+1:]
+	case len(fields) == 2:
+		addr, mask, wildcard = fields[0], fields[1], true
+	case len(fields) == 1:
+		addr, bare = fields[0], true
+	default:
+		return nil, nil, &net.ParseError{Type: "CIDR address", Text: s}
+	}
+
+	p, err := ParseIPLenient(addr)
+	if err != nil || p.Zone != "" {
+		return nil, nil, &net.ParseError{Type: "CIDR address", Text: s}
+	}
+	ip, bits := writtenIPAndBits(addr, p.IP)
+
+	var m net.IPMask
+	switch {
+	case bare:
+		m = net.CIDRMask(bits, bits)
+	case mask != "" && strings.Trim(mask, "0123456789") == "":
+		ones, err := strconv.ParseUint(mask, 10, 8)
+		if err != nil || int(ones) > bits {
+			return nil, nil, &net.ParseError{Type: "CIDR address", Text: s}
+		}
+		m = net.CIDRMask(int(ones), bits)
+	default:
+		if m = parseMask(mask, bits, wildcard); m == nil {
+			return nil, nil, &net.ParseError{Type: "CIDR address", Text: s}
+		}
+	}
+
+	ip, m = shortenMapped(ip, m)
+	return p.IP, &net.IPNet{IP: ip.Mask(m), Mask: m}, nil
+}
+
+// writtenIPAndBits returns ip and its length in bits in the form addr is written in, so that the prefix length of an
+// IPv4-mapped address written as IPv6 is checked against 128 bits.
+func writtenIPAndBits(addr string, ip net.IP) (net.IP, int) {
+	if strings.Contains(addr, ":") {
+		return ip.To16(), 8 * net.IPv6len
+	}
+	return ipAndBits(ip)
+}
+
+// shortenMapped returns an IPv4-mapped network written as IPv6 in its IPv4 form, if its mask covers the mapping prefix.
+func shortenMapped(ip net.IP, m net.IPMask) (net.IP, net.IPMask) {
+	four := ip.To4()
+	if ones, bits := m.Size(); four != nil && bits == 8*net.IPv6len && ones >= 96 {
+		return four, net.CIDRMask(ones-96, 8*net.IPv4len)
+	}
+	return ip, m
+}
+
+// parseMask parses a contiguous netmask or, if wildcard is permitted and the text doesn't have leading one bits, a
+// contiguous wildcard mask. It returns nil if the mask is invalid.
+func parseMask(s string, bits int, wildcard bool) net.IPMask {
+	// the mask must be written in the same IP version as the address
+	if (bits == 8*net.IPv6len) != strings.Contains(s, ":") {
+		return nil
+	}
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil
+	}
+	m := net.IPMask(ip)
+	if bits == 8*net.IPv4len {
+		m = net.IPMask(ip.To4())
+	}
+
+	if wildcard && m[0]&0x80 == 0 {
+		for i := range m {
+			m[i] = ^m[i]
+		}
+	}
+	if ones, mBits := m.Size(); ones == 0 && mBits == 0 {
+		return nil
+	}
+	return m
+}
+
+// Canonicalize returns a copy of ipNet with any host bits cleared and, for IPv4 networks, the address and mask in
+// their 4 byte forms. It returns nil if the mask is not contiguous or doesn't match the address's IP version.
+func Canonicalize(ipNet *net.IPNet) *net.IPNet {
+	ip, bits := ipAndBits(ipNet.IP)
+	ones, mBits := ipNet.Mask.Size()
+	switch {
+	case ip == nil:
+		return nil
+	case mBits == bits:
+	case bits == 8*net.IPv4len && mBits == 8*net.IPv6len && ones >= 96 && allFF(ipNet.Mask[:12]):
+		// a v4 mask in its 16 byte form
+		ones -= 96
+	default:
+		return nil
+	}
+	m := net.CIDRMask(ones, bits)
+	return &net.IPNet{IP: ip.Mask(m), Mask: m}
+}
+
+// ipAndBits returns the shortest form of ip and its length in bits.
+func ipAndBits(ip net.IP) (net.IP, int) {
+	if four := ip.To4(); four != nil {
+		return four, 8 * net.IPv4len
+	}
+	if len(ip) != net.IPv6len {
+		return nil, 0
+	}
+	return ip, 8 * net.IPv6len
+}
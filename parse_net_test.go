@@ -0,0 +1,154 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleParseNetLenient() {
+	for _, s := range []string{"10.0.0.5/255.255.255.0", "10.0.0.0 0.0.0.255", "10.0.0.1", "2001:db8::1/64"} {
+		ip, ipNet, _ := ipx.ParseNetLenient(s)
+		fmt.Println(ip, ipNet)
+	}
+	// Output:
+	// 10.0.0.5 10.0.0.0/24
+	// 10.0.0.0 10.0.0.0/24
+	// 10.0.0.1 10.0.0.1/32
+	// 2001:db8::1 2001:db8::/64
+}
+
+func TestParseNetStrict(t *testing.T) {
+	for _, c := range []struct {
+		in, expected string
+	}{
+		{"10.0.0.0/24", "10.0.0.0/24"},
+		{"0.0.0.0/0", "0.0.0.0/0"},
+		{"10.0.0.1/32", "10.0.0.1/32"},
+		{"2001:db8::/32", "2001:db8::/32"},
+		{"::/0", "::/0"},
+		{"::ffff:10.0.0.0/104", "10.0.0.0/8"},
+		{"::ffff:0:0/96", "0.0.0.0/0"},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			ipNet, err := ipx.ParseNetStrict(c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ipNet.String() != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, ipNet)
+			}
+			if len(ipNet.IP) != len(ipNet.Mask) {
+				t.Fatalf("expected IP and mask of same length but got %v and %v", len(ipNet.IP), len(ipNet.Mask))
+			}
+		})
+	}
+
+	for _, in := range []string{
+		"10.0.0.1/24", "2001:db8::1/32", "10.0.0.0", "10.0.0.0/33", "10.0.0.0/024", "10.0.0.0/", "010.0.0.0/8",
+		"10.0/16", "::/129", "10.0.0.0/255.255.255.0", "::ffff:10.0.0.0/8", "::ffff:0:0/95",
+	} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			if ipNet, err := ipx.ParseNetStrict(in); err == nil {
+				t.Fatalf("expected error but got %v", ipNet)
+			}
+		})
+	}
+}
+
+func TestParseNetLenient(t *testing.T) {
+	for _, c := range []struct {
+		in, ip, net string
+	}{
+		{"10.0.0.1/24", "10.0.0.1", "10.0.0.0/24"},
+		{"10.0.0.0/255.255.255.0", "10.0.0.0", "10.0.0.0/24"},
+		{"10.0.0.0/0.0.0.0", "10.0.0.0", "0.0.0.0/0"},
+		{"10.0.0.0 255.255.0.0", "10.0.0.0", "10.0.0.0/16"},
+		{"10.0.0.0 0.0.0.255", "10.0.0.0", "10.0.0.0/24"},
+		{"10.0.0.1 0.0.0.0", "10.0.0.1", "10.0.0.1/32"},
+		{"10.0.0.1 255.255.255.255", "10.0.0.1", "10.0.0.1/32"},
+		{"10.0.0.1", "10.0.0.1", "10.0.0.1/32"},
+		{" 10.0.0.1 ", "10.0.0.1", "10.0.0.1/32"},
+		{"10.1/16", "10.0.0.1", "10.0.0.0/16"},
+		{"2001:db8::1", "2001:db8::1", "2001:db8::1/128"},
+		{"2001:db8::1/ffff:ffff::", "2001:db8::1", "2001:db8::/32"},
+		{"::ffff:0:0/96", "0.0.0.0", "0.0.0.0/0"},
+		{"::ffff:10.0.0.1/104", "10.0.0.1", "10.0.0.0/8"},
+		{"::ffff:10.0.0.1/ffff:ffff:ffff:ffff:ffff:ffff:ffff:ff00", "10.0.0.1", "10.0.0.0/24"},
+		{"::ffff:10.0.0.1/8", "10.0.0.1", "::/8"},
+		{"::ffff:10.0.0.1", "10.0.0.1", "10.0.0.1/32"},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			ip, ipNet, err := ipx.ParseNetLenient(c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if ip.String() != c.ip || ipNet.String() != c.net {
+				t.Fatalf("expected %v %v but got %v %v", c.ip, c.net, ip, ipNet)
+			}
+		})
+	}
+
+	for _, in := range []string{
+		"", "10.0.0.0/", "10.0.0.0/33", "10.0.0.0/255.0.255.0", "10.0.0.0 0.255.0.255", "10.0.0.0 1 2",
+		"2001:db8::/255.255.0.0", "10.0.0.0/ffff::", "fe80::1%eth0/64", "10.0.0.0/-1",
+	} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			if _, ipNet, err := ipx.ParseNetLenient(in); err == nil {
+				t.Fatalf("expected error but got %v", ipNet)
+			}
+		})
+	}
+}
+
+func TestCanonicalize(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		in       *net.IPNet
+		expected string
+	}{
+		{"host bits", &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.CIDRMask(24, 32)}, "10.0.0.0/24"},
+		{"v4 16 byte mask", &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.CIDRMask(120, 128)}, "10.0.0.0/24"},
+		{"v6", &net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(32, 128)}, "2001:db8::/32"},
+		{"non-contiguous", &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.IPv4Mask(255, 0, 255, 0)}, "<nil>"},
+		{"mismatch", &net.IPNet{IP: net.ParseIP("2001:db8::1"), Mask: net.CIDRMask(24, 32)}, "<nil>"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got := ipx.Canonicalize(c.in)
+			if got.String() != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+			if got != nil && (len(got.IP) != len(got.Mask)) {
+				t.Fatalf("expected IP and mask of same length but got %v and %v", len(got.IP), len(got.Mask))
+			}
+		})
+	}
+}
+
+func TestHostBitsIgnored(t *testing.T) {
+	nonCanonical := &net.IPNet{IP: net.ParseIP("10.0.0.1"), Mask: net.CIDRMask(24, 32)}
+
+	if got := ipx.Collapse([]*net.IPNet{nonCanonical, cidr("10.0.1.0/24")}); fmt.Sprint(got) != "[10.0.0.0/23]" {
+		t.Errorf("expected collapse to ignore host bits but got %v", got)
+	}
+
+	split := ipx.Split(nonCanonical, 25)
+	var nets []string
+	for split.Next() {
+		nets = append(nets, split.Net().String())
+	}
+	if fmt.Sprint(nets) != "[10.0.0.0/25 10.0.0.128/25]" {
+		t.Errorf("expected split to ignore host bits but got %v", nets)
+	}
+
+	addrs := ipx.Addresses(nonCanonical)
+	if !addrs.Next() || addrs.IP().String() != "10.0.0.0" {
+		t.Errorf("expected addresses to start at network address but got %v", addrs.IP())
+	}
+
+	iter := ipx.IterNet(nonCanonical, 1, nil)
+	if !iter.Next() || iter.Net().String() != "10.0.0.0/24" {
+		t.Errorf("expected net iteration to start at network address but got %v", iter.Net())
+	}
+}
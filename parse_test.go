@@ -0,0 +1,103 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"testing"
+)
+
+func ExampleParseIPLenient() {
+	p, _ := ipx.ParseIPLenient("0x0a.1")
+	fmt.Println(p.IP, p.Notation)
+	// Output:
+	// 10.0.0.1 short|hex
+}
+
+func TestParseIPLenient(t *testing.T) {
+	for _, c := range []struct {
+		in, ip, zone string
+		notation     ipx.IPNotation
+	}{
+		{"10.0.0.1", "10.0.0.1", "", 0},
+		{"10.1", "10.0.0.1", "", ipx.NotationShort},
+		{"10.1.1", "10.1.0.1", "", ipx.NotationShort},
+		{"10.65536", "10.1.0.0", "", ipx.NotationShort},
+		{"0x0a.0.0.1", "10.0.0.1", "", ipx.NotationHex},
+		{"012.0.0.1", "10.0.0.1", "", ipx.NotationOctal},
+		{"0.0.0.0", "0.0.0.0", "", 0},
+		{"167772161", "10.0.0.1", "", ipx.NotationInteger},
+		{"0xffffffff", "255.255.255.255", "", ipx.NotationInteger | ipx.NotationHex},
+		{"2001:db8::1", "2001:db8::1", "", ipx.NotationIPv6},
+		{"fe80::1%eth0", "fe80::1", "eth0", ipx.NotationIPv6 | ipx.NotationZone},
+		{"::ffff:192.0.2.1", "192.0.2.1", "", ipx.NotationIPv6 | ipx.NotationV4Mapped},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			p, err := ipx.ParseIPLenient(c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.IP.String() != c.ip || p.Zone != c.zone || p.Notation != c.notation {
+				t.Fatalf("expected %v %q %v but got %v %q %v", c.ip, c.zone, c.notation, p.IP, p.Zone, p.Notation)
+			}
+		})
+	}
+
+	for _, in := range []string{
+		"", "1.2.3.4.5", "256.0.0.1", "10.16777216", "08.0.0.1", "0x.0.0.1", "1..2.3", "1.2.3.4.", "-1", "4294967296",
+		"fe80::1%", "2001:db8::g", " 1.2.3.4",
+	} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			if p, err := ipx.ParseIPLenient(in); err == nil {
+				t.Fatalf("expected error but got %v", p.IP)
+			}
+		})
+	}
+}
+
+func TestParseIPStrict(t *testing.T) {
+	for _, c := range []struct {
+		in, ip   string
+		notation ipx.IPNotation
+	}{
+		{"10.0.0.1", "10.0.0.1", 0},
+		{"255.255.255.255", "255.255.255.255", 0},
+		{"2001:0db8::1", "2001:db8::1", ipx.NotationIPv6},
+		{"::ffff:192.0.2.1", "192.0.2.1", ipx.NotationIPv6 | ipx.NotationV4Mapped},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			p, err := ipx.ParseIPStrict(c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.IP.String() != c.ip || p.Notation != c.notation {
+				t.Fatalf("expected %v %v but got %v %v", c.ip, c.notation, p.IP, p.Notation)
+			}
+		})
+	}
+
+	for _, in := range []string{
+		"10.1", "012.0.0.1", "0x0a.0.0.1", "167772161", "10.0.0.01", "1.2.3.4.5", "256.0.0.1", "fe80::1%eth0",
+		"::ffff:192.0.02.1", "+1.2.3.4",
+	} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			if p, err := ipx.ParseIPStrict(in); err == nil {
+				t.Fatalf("expected error but got %v", p.IP)
+			}
+		})
+	}
+}
+
+func TestIPNotationString(t *testing.T) {
+	for _, c := range []struct {
+		n        ipx.IPNotation
+		expected string
+	}{
+		{0, "canonical"},
+		{ipx.NotationIPv6 | ipx.NotationZone, "ipv6|zone"},
+		{ipx.NotationOctal, "octal"},
+	} {
+		if got := c.n.String(); got != c.expected {
+			t.Errorf("expected %v but got %v", c.expected, got)
+		}
+	}
+}
@@ -0,0 +1,276 @@
+package ipx
+
+import (
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AddrPattern is a set of IPv4 addresses written as a pattern of octets, as used by nmap and some firewalls. Each
+// octet is a comma separated list of values, ranges and wildcards, e.g. 10.0.*.1-10 or 10.0.0.1,5,9, and the pattern
+// may end with a prefix length, e.g. 192.168.1-3.0/24, which matches every address in the networks of that length
+// containing a matching address.
+type AddrPattern struct {
+	octets [4][]octetRange // sorted and disjoint
+}
+
+type octetRange struct {
+	lo, hi uint8
+}
+
+// ParseAddrPattern parses s as an AddrPattern. Octet ranges may omit either bound, which defaults to 0 or 255, and a
+// lone "*" or "-" matches any value.
+func ParseAddrPattern(s string) (*AddrPattern, error) {
+	text, hostBits := s, 0
+	if i := strings.IndexByte(s, '/'); i >= 0 {
+		ones, err := strconv.ParseUint(s[i+1:], 10, 8)
+		if err != nil || ones > 32 {
+			return nil, &net.ParseError{Type: "address pattern", Text: s}
+		}
+		text, hostBits = s[:i], 32-int(ones)
+	}
+
+	parts := strings.Split(text, ".")
+	if len(parts) != 4 {
+		return nil, &net.ParseError{Type: "address pattern", Text: s}
+	}
+
+	p := new(AddrPattern)
+	for i, part := range parts {
+		ranges, ok := parseOctetRanges(part)
+		if !ok {
+			return nil, &net.ParseError{Type: "address pattern", Text: s}
+		}
+
+		// widen each value to cover the bits of this octet which fall within the host bits
+		if octetHostBits := hostBits - 8*(3-i); octetHostBits > 0 {
+			if octetHostBits > 8 {
+				octetHostBits = 8
+			}
+			m := uint8(1<<uint(octetHostBits) - 1)
+			for j := range ranges {
+				ranges[j].lo &^= m
+				ranges[j].hi |= m
+			}
+		}
+		p.octets[i] = mergeOctetRanges(ranges)
+	}
+	return p, nil
+}
+
+func parseOctetRanges(s string) ([]octetRange, bool) {
+	var ranges []octetRange
+	for _, elem := range strings.Split(s, ",") {
+		if elem == "*" || elem == "-" {
+			ranges = append(ranges, octetRange{0, 255})
+			continue
+		}
+		lo, hi := elem, elem
+		if i := strings.IndexByte(elem, '-'); i >= 0 {
+			lo, hi = elem[:i], elem[i+1:]
+			if lo == "" {
+				lo = "0"
+			}
+			if hi == "" {
+				hi = "255"
+			}
+		}
+		l, err := strconv.ParseUint(lo, 10, 8)
+		if err != nil {
+			return nil, false
+		}
+		h, err := strconv.ParseUint(hi, 10, 8)
+		if err != nil || h < l {
+			return nil, false
+		}
+		ranges = append(ranges, octetRange{uint8(l), uint8(h)})
+	}
+	return ranges, true
+}
+
+func mergeOctetRanges(ranges []octetRange) []octetRange {
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].lo < ranges[j].lo
+	})
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if int(r.lo) <= int(last.hi)+1 {
+			if r.hi > last.hi {
+				last.hi = r.hi
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// String returns the pattern with each octet's values sorted and merged, and "*" for octets matching any value.
+func (p *AddrPattern) String() string {
+	parts := make([]string, 4)
+	for i, ranges := range p.octets {
+		if isFullOctet(ranges) {
+			parts[i] = "*"
+			continue
+		}
+		elems := make([]string, 0, len(ranges))
+		for _, r := range ranges {
+			if r.lo == r.hi {
+				elems = append(elems, strconv.Itoa(int(r.lo)))
+				continue
+			}
+			elems = append(elems, strconv.Itoa(int(r.lo))+"-"+strconv.Itoa(int(r.hi)))
+		}
+		parts[i] = strings.Join(elems, ",")
+	}
+	return strings.Join(parts, ".")
+}
+
+func isFullOctet(ranges []octetRange) bool {
+	return len(ranges) == 1 && ranges[0] == octetRange{0, 255}
+}
+
+// Contains returns whether ip matches the pattern, without expanding it.
+func (p *AddrPattern) Contains(ip net.IP) bool {
+	four := ip.To4()
+	if four == nil {
+		return false
+	}
+	for i, ranges := range p.octets {
+		j := sort.Search(len(ranges), func(j int) bool {
+			return ranges[j].hi >= four[i]
+		})
+		if j == len(ranges) || ranges[j].lo > four[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Size returns the number of addresses matching the pattern.
+func (p *AddrPattern) Size() uint64 {
+	size := uint64(1)
+	for _, ranges := range p.octets {
+		var n uint64
+		for _, r := range ranges {
+			n += uint64(r.hi-r.lo) + 1
+		}
+		size *= n
+	}
+	return size
+}
+
+// Addresses returns all of the addresses matching the pattern in ascending order.
+func (p *AddrPattern) Addresses() *AddrPatternIter {
+	return &AddrPatternIter{runs: newPatternRuns(p), ips: new(IPIter)}
+}
+
+// Nets returns the minimal list of networks covering exactly the addresses matching the pattern.
+func (p *AddrPattern) Nets() []*net.IPNet {
+	var nets []*net.IPNet
+	for runs := newPatternRuns(p); ; {
+		first, last, ok := runs.next()
+		if !ok {
+			break
+		}
+		nets = append(nets, summarizeRange4(first, last)...)
+	}
+	return Collapse(nets)
+}
+
+// AddrPatternIter permits iteration over the addresses matching an AddrPattern.
+type AddrPatternIter struct {
+	runs patternRuns
+	ips  *IPIter
+}
+
+// IP returns the most recent IP; the underlying type may be modified on later calls to `Next`.
+// It does no allocation.
+func (i *AddrPatternIter) IP() net.IP {
+	return i.ips.IP()
+}
+
+// Next returns true when the underlying pointer has been successfully updated with the next value.
+func (i *AddrPatternIter) Next() bool {
+	for !i.ips.Next() {
+		first, last, ok := i.runs.next()
+		if !ok {
+			return false
+		}
+		if i.ips.ip == nil {
+			i.ips = iterIPv4(first, 1, last)
+			continue
+		}
+		// reuse the iter and its buffer for the next run
+		i.ips.v4 = v4IPIter{first, first, 1, last}
+		i.ips.flags = ipIterFlagActive
+	}
+	return true
+}
+
+// patternRuns enumerates the contiguous runs of addresses matching a pattern in ascending order. The octets after the
+// last octet not matching every value contribute nothing but the run length, so runs are formed from each range of
+// that octet combined with every value of the octets before it.
+type patternRuns struct {
+	octets [4][]octetRange
+	last   int    // index of the last octet not matching every value, or -1
+	ranges [4]int // index of the current range of each octet up to last
+	values [4]int // current value of each octet before last
+	done   bool
+}
+
+func newPatternRuns(p *AddrPattern) patternRuns {
+	r := patternRuns{octets: p.octets, last: -1}
+	for i, ranges := range p.octets {
+		if !isFullOctet(ranges) {
+			r.last = i
+		}
+	}
+	for i := 0; i < r.last; i++ {
+		r.values[i] = int(r.octets[i][0].lo)
+	}
+	return r
+}
+
+func (r *patternRuns) next() (first, last uint32, ok bool) {
+	if r.done {
+		return 0, 0, false
+	}
+	if r.last < 0 {
+		r.done = true
+		return 0, maxUint32, true
+	}
+
+	for i := 0; i < r.last; i++ {
+		first |= uint32(r.values[i]) << uint(24-8*i)
+	}
+	rng := r.octets[r.last][r.ranges[r.last]]
+	shift := uint(24 - 8*r.last)
+	last = first | uint32(rng.hi)<<shift | (1<<shift - 1)
+	first |= uint32(rng.lo) << shift
+
+	r.advance()
+	return first, last, true
+}
+
+// advance steps to the next run like an odometer, with the range of the last octet turning fastest.
+func (r *patternRuns) advance() {
+	if r.ranges[r.last]++; r.ranges[r.last] < len(r.octets[r.last]) {
+		return
+	}
+	r.ranges[r.last] = 0
+	for i := r.last - 1; i >= 0; i-- {
+		if r.values[i]++; r.values[i] <= int(r.octets[i][r.ranges[i]].hi) {
+			return
+		}
+		if r.ranges[i]++; r.ranges[i] < len(r.octets[i]) {
+			r.values[i] = int(r.octets[i][r.ranges[i]].lo)
+			return
+		}
+		r.ranges[i] = 0
+		r.values[i] = int(r.octets[i][0].lo)
+	}
+	r.done = true
+}
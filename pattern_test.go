@@ -0,0 +1,151 @@
+package ipx_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleAddrPattern() {
+	p, _ := ipx.ParseAddrPattern("10.0.0.1,5,9")
+	for iter := p.Addresses(); iter.Next(); {
+		fmt.Println(iter.IP())
+	}
+	// Output:
+	// 10.0.0.1
+	// 10.0.0.5
+	// 10.0.0.9
+}
+
+func ExampleAddrPattern_Nets() {
+	p, _ := ipx.ParseAddrPattern("192.168.1-3.0/24")
+	fmt.Println(p, p.Size(), p.Nets())
+	// Output:
+	// 192.168.1-3.* 768 [192.168.1.0/24 192.168.2.0/23]
+}
+
+func TestParseAddrPattern(t *testing.T) {
+	for _, c := range []struct {
+		in, expected string
+	}{
+		{"10.0.*.1", "10.0.*.1"},
+		{"10.0.*.1-10", "10.0.*.1-10"},
+		{"10.0.0.9,1,5,2-4", "10.0.0.1-5,9"},
+		{"10.0.0.-", "10.0.0.*"},
+		{"10.0.0.-9", "10.0.0.0-9"},
+		{"10.0.0.250-", "10.0.0.250-255"},
+		{"10.0.0.0-255", "10.0.0.*"},
+		{"10.0.1,5.0/23", "10.0.0-1,4-5.*"},
+		{"10.0.0.9/30", "10.0.0.8-11"},
+		{"1.2.3.4/0", "*.*.*.*"},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			p, err := ipx.ParseAddrPattern(c.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if p.String() != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, p)
+			}
+		})
+	}
+
+	for _, in := range []string{"", "10.0.0", "10.0.0.0.0", "10.0.0.256", "10.0.0.5-1", "10.0.0.0/33", "10.0.0.x", "10.0..1"} {
+		t.Run("invalid "+in, func(t *testing.T) {
+			if p, err := ipx.ParseAddrPattern(in); err == nil {
+				t.Fatalf("expected error but got %v", p)
+			}
+		})
+	}
+}
+
+func TestAddrPatternContains(t *testing.T) {
+	p, _ := ipx.ParseAddrPattern("10.0-1,5.*.1-10,200")
+	for _, c := range []struct {
+		ip       string
+		expected bool
+	}{
+		{"10.0.0.1", true},
+		{"10.1.99.10", true},
+		{"10.5.255.200", true},
+		{"10.2.0.1", false},
+		{"10.0.0.11", false},
+		{"10.0.0.0", false},
+		{"11.0.0.1", false},
+		{"::ffff:10.5.0.5", true},
+		{"2001:db8::1", false},
+	} {
+		if got := p.Contains(net.ParseIP(c.ip)); got != c.expected {
+			t.Errorf("expected %v for %v but got %v", c.expected, c.ip, got)
+		}
+	}
+}
+
+func TestAddrPatternAddresses(t *testing.T) {
+	for _, s := range []string{
+		"10.0.0.1,5,9",
+		"10.0-1.2,4.250-",
+		"10.0.254-255.*",
+		"10.0.*.1/31",
+		"255.255.255.254-",
+		"0.0.0.0-3",
+	} {
+		t.Run(s, func(t *testing.T) {
+			p, err := ipx.ParseAddrPattern(s)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var (
+				count uint64
+				prev  net.IP
+			)
+			for iter := p.Addresses(); iter.Next(); count++ {
+				ip := iter.IP()
+				if !p.Contains(ip) {
+					t.Fatalf("%v does not contain %v", p, ip)
+				}
+				if prev != nil && bytes.Compare(prev.To4(), ip.To4()) != -1 {
+					t.Fatalf("%v not after %v", ip, prev)
+				}
+				prev = append(prev[:0], ip...)
+			}
+			if count != p.Size() {
+				t.Fatalf("expected %v addresses but got %v", p.Size(), count)
+			}
+
+			var covered uint64
+			for _, n := range p.Nets() {
+				ones, bits := n.Mask.Size()
+				covered += 1 << uint(bits-ones)
+				if !p.Contains(n.IP) || !p.Contains(ipx.Broadcast(n)) {
+					t.Fatalf("%v not within %v", n, p)
+				}
+			}
+			if covered != p.Size() {
+				t.Fatalf("expected nets to cover %v addresses but covered %v", p.Size(), covered)
+			}
+		})
+	}
+}
+
+func TestAddrPatternNets(t *testing.T) {
+	for _, c := range []struct {
+		in, expected string
+	}{
+		{"10.0.0.1,5,9", "[10.0.0.1/32 10.0.0.5/32 10.0.0.9/32]"},
+		{"10.*.*.*", "[10.0.0.0/8]"},
+		{"10.0-3.*.*", "[10.0.0.0/14]"},
+		{"10.0.0-1.0-127", "[10.0.0.0/25 10.0.1.0/25]"},
+		{"*.*.*.*", "[0.0.0.0/0]"},
+	} {
+		t.Run(c.in, func(t *testing.T) {
+			p, _ := ipx.ParseAddrPattern(c.in)
+			if got := fmt.Sprint(p.Nets()); got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
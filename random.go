@@ -0,0 +1,153 @@
+package ipx
+
+import (
+	"math/rand"
+	"net"
+)
+
+// RandomIP returns an address of the network chosen uniformly at random using src. It returns nil if the network is
+// invalid. Host bits set in ipNet are ignored. IPv4 addresses are returned in their 4 byte form.
+func RandomIP(ipNet *net.IPNet, src rand.Source) net.IP {
+	s, four, ok := netSpan(ipNet)
+	if !ok {
+		return nil
+	}
+	return randomIP(randIn(src, s.first, s.last), four)
+}
+
+// RandomHost returns one of the addresses Hosts would return for the network chosen uniformly at random using src,
+// e.g. a random interface identifier within an IPv6 /64. It returns nil if the network is invalid. Host bits set in
+// ipNet are ignored. IPv4 addresses are returned in their 4 byte form.
+func RandomHost(ipNet *net.IPNet, src rand.Source) net.IP {
+	n := Canonicalize(ipNet)
+	if n == nil {
+		return nil
+	}
+	first, last, _ := Hosts(n).bounds()
+	return randomIP(randIn(src, first, last), n.IP.To4() != nil)
+}
+
+// RandomSubnet returns a subnet of the network with the given prefix length chosen uniformly at random using src. It
+// returns nil if the network is invalid or the prefix length is shorter than the network's or longer than its
+// addresses. Host bits set in ipNet are ignored. The IP of an IPv4 subnet is in its 4 byte form.
+func RandomSubnet(ipNet *net.IPNet, prefix int, src rand.Source) *net.IPNet {
+	n := Canonicalize(ipNet)
+	if n == nil {
+		return nil
+	}
+	ones, bits := n.Mask.Size()
+	if prefix < ones || prefix > bits {
+		return nil
+	}
+	first, _ := IPToUint128(n.IP)
+	last := first.Or(Uint128{0, 1}.Lsh(uint(bits - ones)).Minus(Uint128{0, 1}))
+	addr := randIn(src, first, last).AndNot(Uint128{0, 1}.Lsh(uint(bits - prefix)).Minus(Uint128{0, 1}))
+	return &net.IPNet{IP: randomIP(addr, bits == 8*net.IPv4len), Mask: net.CIDRMask(prefix, bits)}
+}
+
+// SampleN returns n distinct addresses of the set chosen at random using src, in random order, or every address of
+// the set if it has no more than n. The addresses are drawn from a permutation of the set as Shuffle makes, so
+// sampling takes time proportional to n however large the set. IPv4 addresses are returned in their 4 byte form.
+func SampleN(set *NetSet, n int, src rand.Source) []net.IP {
+	var ips []net.IP
+	for s := ShuffleSet(set, src.Int63()); len(ips) < n && s.Next(); {
+		ip := s.IP()
+		if four := ip.To4(); four != nil {
+			ip = four
+		}
+		ips = append(ips, append(net.IP(nil), ip...))
+	}
+	return ips
+}
+
+// ChooseNet returns one of the networks chosen at random using src, with each network's chance of being chosen in
+// proportion to its number of addresses. Networks with invalid masks are never chosen; nil is returned if there are no
+// others.
+func ChooseNet(nets []*net.IPNet, src rand.Source) *net.IPNet {
+	// the weights are the sizes of the networks, which are powers of two; if they sum to more than 2^128, they're
+	// scaled down until they fit, at the cost of never choosing networks whose share is below 2^-128
+	sizes := make([]int, len(nets))
+	for i, n := range nets {
+		sizes[i] = -1
+		if n := Canonicalize(n); n != nil {
+			ones, bits := n.Mask.Size()
+			sizes[i] = bits - ones
+		}
+	}
+	for shift := 0; shift <= 128; shift++ {
+		total, ok := Uint128{}, true
+		for _, size := range sizes {
+			if size < shift {
+				continue
+			}
+			if total, ok = addOK(total, Uint128{0, 1}.Lsh(uint(size-shift))); !ok {
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		if total.IsZero() {
+			return nil
+		}
+
+		pick := randIn(src, Uint128{}, total.Minus(Uint128{0, 1}))
+		for i, size := range sizes {
+			if size < shift {
+				continue
+			}
+			w := Uint128{0, 1}.Lsh(uint(size - shift))
+			if pick.Cmp(w) == -1 {
+				return nets[i]
+			}
+			pick = pick.Minus(w)
+		}
+	}
+	return nil
+}
+
+// addOK returns a + b and whether the sum fits in a Uint128. A weight of 2^128 wraps to zero in Lsh, which is
+// reported as not fitting.
+func addOK(a, b Uint128) (Uint128, bool) {
+	if b.IsZero() {
+		return a, false
+	}
+	sum, overflow := a.AddOverflow(b)
+	return sum, !overflow
+}
+
+// randomIP returns the address with the integer value u, in its 4 byte form if four is set.
+func randomIP(u Uint128, four bool) net.IP {
+	if four {
+		ip := make(net.IP, net.IPv4len)
+		from32(uint32(u.L), ip)
+		return ip
+	}
+	return Uint128ToIP(u, true)
+}
+
+// randIn returns a value between first and last, inclusive, chosen uniformly at random using src.
+func randIn(src rand.Source, first, last Uint128) Uint128 {
+	// draw as many bits as the distance needs, rejecting values past it; at least half the draws are accepted
+	dist := last.Minus(first)
+	mask := Uint128{maxUint64, maxUint64}.Rsh(uint(dist.LeadingZeros()))
+	for {
+		v := Uint128{0, randUint64(src)}
+		if mask.H != 0 {
+			v.H = randUint64(src)
+		}
+		v = v.And(mask)
+		if v.Cmp(dist) != 1 {
+			return first.Add(v)
+		}
+	}
+}
+
+// randUint64 returns 64 random bits from src.
+func randUint64(src rand.Source) uint64 {
+	if s, ok := src.(rand.Source64); ok {
+		return s.Uint64()
+	}
+	// Int63 supplies 63 bits at a time, as in math/rand
+	return uint64(src.Int63())>>31 | uint64(src.Int63())<<32
+}
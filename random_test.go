@@ -0,0 +1,203 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"math/rand"
+	"net"
+	"testing"
+)
+
+func ExampleRandomHost() {
+	src := rand.NewSource(1)
+	ip := ipx.RandomHost(cidr("2001:db8:1:2::/64"), src)
+	fmt.Println(cidr("2001:db8:1:2::/64").Contains(ip))
+	// Output:
+	// true
+}
+
+// int63Source hides any Uint64 method of the source, leaving only Int63.
+type int63Source struct {
+	rand.Source
+}
+
+func TestRandomIP(t *testing.T) {
+	for _, c := range []struct {
+		net      string
+		expected int // number of distinct addresses expected
+	}{
+		{"10.0.0.1/32", 1},
+		{"10.0.0.0/30", 4},
+		{"255.255.255.252/30", 4},
+		{"2001:db8::/126", 4},
+		{"2001:db8::/64", 1000},
+		{"0.0.0.0/0", 1000},
+		{"::/0", 1000},
+	} {
+		for _, src := range []rand.Source{rand.NewSource(1), int63Source{rand.NewSource(1)}} {
+			t.Run(fmt.Sprintf("%v %T", c.net, src), func(t *testing.T) {
+				n := cidr(c.net)
+				seen := make(map[string]bool)
+				for i := 0; i < 1000; i++ {
+					ip := ipx.RandomIP(n, src)
+					if !n.Contains(ip) || len(ip) != len(n.IP) {
+						t.Fatalf("%v is not in %v", ip, n)
+					}
+					seen[ip.String()] = true
+				}
+				if len(seen) != c.expected {
+					t.Fatalf("expected %v distinct addresses but got %v", c.expected, len(seen))
+				}
+			})
+		}
+	}
+}
+
+func TestRandomHost(t *testing.T) {
+	for _, c := range []struct {
+		net      string
+		expected []string
+	}{
+		{"10.0.0.0/29", []string{"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6"}},
+		{"10.0.0.4/31", []string{"10.0.0.4", "10.0.0.5"}},
+		{"2001:db8::/126", []string{"2001:db8::1", "2001:db8::2", "2001:db8::3"}},
+	} {
+		t.Run(c.net, func(t *testing.T) {
+			src := rand.NewSource(2)
+			seen := make(map[string]bool)
+			for i := 0; i < 1000; i++ {
+				ip := ipx.RandomHost(cidr(c.net), src)
+				if len(ip) != len(cidr(c.net).IP) {
+					t.Fatalf("expected %v bytes but got %v", len(cidr(c.net).IP), len(ip))
+				}
+				seen[ip.String()] = true
+			}
+			expected := make(map[string]bool)
+			for _, e := range c.expected {
+				expected[e] = true
+			}
+			if fmt.Sprint(seen) != fmt.Sprint(expected) {
+				t.Fatalf("expected %v but got %v", expected, seen)
+			}
+		})
+	}
+}
+
+func TestRandomSubnet(t *testing.T) {
+	src := rand.NewSource(3)
+	for _, c := range []struct {
+		net    string
+		prefix int
+	}{
+		{"10.0.0.0/8", 24},
+		{"10.0.0.0/8", 8},
+		{"10.0.0.0/8", 32},
+		{"0.0.0.0/0", 16},
+		{"2001:db8::/32", 64},
+		{"::/0", 48},
+		{"::ffff:10.0.0.0/104", 24},
+	} {
+		t.Run(fmt.Sprint(c.net, " ", c.prefix), func(t *testing.T) {
+			n := cidr(c.net)
+			for i := 0; i < 100; i++ {
+				s := ipx.RandomSubnet(n, c.prefix, src)
+				if ones, _ := s.Mask.Size(); ones != c.prefix {
+					t.Fatalf("expected a /%v but got %v", c.prefix, s)
+				}
+				first, last := ipx.NetToRange(s)
+				if !first.Equal(s.IP) || !n.Contains(first) || !n.Contains(last) {
+					t.Fatalf("%v is not a subnet of %v", s, n)
+				}
+				if (s.IP.To4() != nil) != (len(s.IP) == net.IPv4len) {
+					t.Fatalf("expected an IPv4 subnet in 4 byte form but got %v bytes", len(s.IP))
+				}
+			}
+		})
+	}
+	if s := ipx.RandomSubnet(cidr("10.0.0.0/8"), 7, src); s != nil {
+		t.Fatalf("expected nil for a shorter prefix but got %v", s)
+	}
+	if s := ipx.RandomSubnet(cidr("10.0.0.0/8"), 33, src); s != nil {
+		t.Fatalf("expected nil for a longer prefix but got %v", s)
+	}
+}
+
+func TestRandomInvalid(t *testing.T) {
+	invalid := &net.IPNet{IP: net.IPv4zero, Mask: net.IPMask{0xff, 0, 0xff, 0}}
+	src := rand.NewSource(1)
+	if ip := ipx.RandomIP(invalid, src); ip != nil {
+		t.Errorf("expected nil but got %v", ip)
+	}
+	if ip := ipx.RandomHost(invalid, src); ip != nil {
+		t.Errorf("expected nil but got %v", ip)
+	}
+	if n := ipx.RandomSubnet(invalid, 24, src); n != nil {
+		t.Errorf("expected nil but got %v", n)
+	}
+	if n := ipx.ChooseNet([]*net.IPNet{invalid}, src); n != nil {
+		t.Errorf("expected nil but got %v", n)
+	}
+	if n := ipx.ChooseNet(nil, src); n != nil {
+		t.Errorf("expected nil but got %v", n)
+	}
+}
+
+func TestSampleN(t *testing.T) {
+	set := ipx.NewNetSet(cidrs([]string{"10.0.0.0/24", "192.0.2.0/28", "2001:db8::/120"}))
+	src := rand.NewSource(4)
+
+	sample := ipx.SampleN(set, 100, src)
+	if len(sample) != 100 {
+		t.Fatalf("expected 100 addresses but got %v", len(sample))
+	}
+	seen := make(map[string]bool)
+	for _, ip := range sample {
+		if !set.Contains(ip) || seen[ip.String()] || (ip.To4() != nil) != (len(ip) == net.IPv4len) {
+			t.Fatalf("unexpected %v", ip)
+		}
+		seen[ip.String()] = true
+	}
+
+	if all := ipx.SampleN(set, 1000, src); len(all) != 256+16+256 {
+		t.Fatalf("expected every address but got %v", len(all))
+	}
+	if none := ipx.SampleN(set, 0, src); len(none) != 0 {
+		t.Fatalf("expected no addresses but got %v", none)
+	}
+
+	huge := ipx.NewNetSet(cidrs([]string{"::/0"}))
+	if sample := ipx.SampleN(huge, 10, src); len(sample) != 10 {
+		t.Fatalf("expected 10 addresses but got %v", sample)
+	}
+}
+
+func TestChooseNet(t *testing.T) {
+	nets := cidrs([]string{"10.0.0.0/24", "10.1.0.0/25", "10.2.0.0/26", "10.3.0.0/26"})
+	src := rand.NewSource(5)
+
+	counts := make(map[string]int)
+	const draws = 40000
+	for i := 0; i < draws; i++ {
+		counts[ipx.ChooseNet(nets, src).String()]++
+	}
+	for n, share := range map[string]float64{
+		"10.0.0.0/24": 0.5,
+		"10.1.0.0/25": 0.25,
+		"10.2.0.0/26": 0.125,
+		"10.3.0.0/26": 0.125,
+	} {
+		if got := float64(counts[n]) / draws; got < share*0.9 || got > share*1.1 {
+			t.Errorf("expected %v to be chosen %v of the time but got %v", n, share, got)
+		}
+	}
+
+	// the sizes sum to more than 2^128, and the /128 is too small to ever be chosen
+	huge := cidrs([]string{"::/0", "8000::/1", "2001:db8::1/128"})
+	counts = make(map[string]int)
+	for i := 0; i < 3000; i++ {
+		counts[ipx.ChooseNet(huge, src).String()]++
+	}
+	if len(counts) != 2 || counts["::/0"] < 1800 || counts["::/0"] > 2200 {
+		t.Fatalf("expected ::/0 to be chosen two thirds of the time but got %v", counts)
+	}
+}
@@ -0,0 +1,35 @@
+package ipx
+
+import "net"
+
+// IPRange is the inclusive range of addresses between First and Last.
+type IPRange struct {
+	First, Last net.IP
+}
+
+// String returns the range as the first and last addresses separated by a hyphen.
+func (r IPRange) String() string {
+	return r.First.String() + "-" + r.Last.String()
+}
+
+// span is an inclusive range of addresses as integers; IPv4 addresses occupy the low 32 bits.
+type span struct {
+	first, last Uint128
+}
+
+// spanOf returns the range as integers and whether its addresses are IPv4. It returns false if the range's addresses
+// are invalid or of different versions.
+func spanOf(r IPRange) (s span, four bool, ok bool) {
+	four = r.First.To4() != nil
+	if four != (r.Last.To4() != nil) {
+		return span{}, false, false
+	}
+	var err error
+	if s.first, err = IPToUint128(r.First); err != nil {
+		return span{}, false, false
+	}
+	if s.last, err = IPToUint128(r.Last); err != nil {
+		return span{}, false, false
+	}
+	return s, four, true
+}
@@ -0,0 +1,251 @@
+//go:build go1.23
+// +build go1.23
+
+package ipx
+
+import (
+	"iter"
+	"net"
+	"net/netip"
+)
+
+// ipCursor is implemented by the iterators over addresses.
+type ipCursor interface {
+	Next() bool
+	IP() net.IP
+}
+
+// netCursor is implemented by the iterators over networks.
+type netCursor interface {
+	Next() bool
+	Net() *net.IPNet
+}
+
+// All returns the remaining values of the iter as a sequence. As with `IP`, the yielded value is modified on later
+// steps; use CloneIPs to keep them. It does no allocation per value.
+func (i *IPIter) All() iter.Seq[net.IP] {
+	return ipSeq(i)
+}
+
+// Addrs returns the remaining values of the iter as a sequence of netip.Addr, which are safe to keep. IPv4 and
+// IPv4-mapped addresses are yielded as IPv4. It does no allocation per value.
+func (i *IPIter) Addrs() iter.Seq[netip.Addr] {
+	return addrSeq(i)
+}
+
+// All returns the remaining values of the iter as a sequence, as IPIter.All does.
+func (c *ChainIPIter) All() iter.Seq[net.IP] {
+	return ipSeq(c)
+}
+
+// Addrs returns the remaining values of the iter as a sequence of netip.Addr, as IPIter.Addrs does.
+func (c *ChainIPIter) Addrs() iter.Seq[netip.Addr] {
+	return addrSeq(c)
+}
+
+// All returns the remaining values of the iter as a sequence, as IPIter.All does.
+func (s *ShuffleIter) All() iter.Seq[net.IP] {
+	return ipSeq(s)
+}
+
+// Addrs returns the remaining values of the iter as a sequence of netip.Addr, as IPIter.Addrs does.
+func (s *ShuffleIter) Addrs() iter.Seq[netip.Addr] {
+	return addrSeq(s)
+}
+
+// All returns the remaining values of the iter as a sequence, as IPIter.All does.
+func (i *AddrPatternIter) All() iter.Seq[net.IP] {
+	return ipSeq(i)
+}
+
+// Addrs returns the remaining values of the iter as a sequence of netip.Addr, as IPIter.Addrs does.
+func (i *AddrPatternIter) Addrs() iter.Seq[netip.Addr] {
+	return addrSeq(i)
+}
+
+// All returns the remaining networks of the iter as a sequence. As with `Net`, the yielded value is modified on later
+// steps; use CloneNets to keep them. It does no allocation per value.
+func (n *NetIter) All() iter.Seq[*net.IPNet] {
+	return netSeq(n)
+}
+
+// Prefixes returns the remaining networks of the iter as a sequence of netip.Prefix, which are safe to keep. It does
+// no allocation per value.
+func (n *NetIter) Prefixes() iter.Seq[netip.Prefix] {
+	return prefixSeq(n)
+}
+
+// All returns the remaining subnets of the walk as a sequence, as NetIter.All does. SkipChildren may be called while
+// ranging over it.
+func (w *WalkIter) All() iter.Seq[*net.IPNet] {
+	return netSeq(w)
+}
+
+// Prefixes returns the remaining subnets of the walk as a sequence of netip.Prefix, as NetIter.Prefixes does.
+func (w *WalkIter) Prefixes() iter.Seq[netip.Prefix] {
+	return prefixSeq(w)
+}
+
+// CloneIPs returns a sequence yielding a copy of each value of seq, so that the values are safe to keep.
+func CloneIPs(seq iter.Seq[net.IP]) iter.Seq[net.IP] {
+	return func(yield func(net.IP) bool) {
+		for ip := range seq {
+			if !yield(append(net.IP(nil), ip...)) {
+				return
+			}
+		}
+	}
+}
+
+// CloneNets returns a sequence yielding a copy of each value of seq, so that the values are safe to keep.
+func CloneNets(seq iter.Seq[*net.IPNet]) iter.Seq[*net.IPNet] {
+	return func(yield func(*net.IPNet) bool) {
+		for n := range seq {
+			if !yield(&net.IPNet{IP: append(net.IP(nil), n.IP...), Mask: append(net.IPMask(nil), n.Mask...)}) {
+				return
+			}
+		}
+	}
+}
+
+// SummarizeRangeSeq returns the networks SummarizeRange would as a sequence, computing each as it is needed. The
+// yielded value is modified on later steps; use CloneNets to keep them. It does no allocation per value.
+func SummarizeRangeSeq(first, last net.IP) iter.Seq[*net.IPNet] {
+	return func(yield func(*net.IPNet) bool) {
+		s, four, ok := spanOf(IPRange{First: first, Last: last})
+		if !ok || s.first.Cmp(s.last) == 1 {
+			return
+		}
+		width := 8 * net.IPv6len
+		if four {
+			width = 8 * net.IPv4len
+		}
+		n := &net.IPNet{IP: make(net.IP, width/8), Mask: make(net.IPMask, width/8)}
+		summarizeSpan(s, width, func(addr Uint128, hostBits int) bool {
+			if four {
+				from32(uint32(addr.L), n.IP)
+				from32(uint32(maxUint32)<<uint(hostBits), n.Mask)
+			} else {
+				From128(addr, n.IP)
+				From128(Uint128{maxUint64, maxUint64}.Lsh(uint(hostBits)), n.Mask)
+			}
+			return yield(n)
+		})
+	}
+}
+
+// SummarizeRangePrefixes returns the prefixes which combined cover the range between the first and last addresses,
+// inclusive, as a sequence. Nothing is yielded if the addresses are invalid or of different versions; IPv4-mapped
+// IPv6 addresses are IPv6 here, as they are in netip.
+func SummarizeRangePrefixes(first, last netip.Addr) iter.Seq[netip.Prefix] {
+	return func(yield func(netip.Prefix) bool) {
+		if !first.IsValid() || first.BitLen() != last.BitLen() || last.Less(first) {
+			return
+		}
+		four := first.Is4()
+		width := first.BitLen()
+		s := span{addrToUint128(first), addrToUint128(last)}
+		summarizeSpan(s, width, func(addr Uint128, hostBits int) bool {
+			var a netip.Addr
+			if four {
+				a = netip.AddrFrom4([4]byte{byte(addr.L >> 24), byte(addr.L >> 16), byte(addr.L >> 8), byte(addr.L)})
+			} else {
+				var b [net.IPv6len]byte
+				From128(addr, b[:])
+				a = netip.AddrFrom16(b)
+			}
+			return yield(netip.PrefixFrom(a, width-hostBits))
+		})
+	}
+}
+
+// summarizeSpan calls fn with the address and number of host bits of each of the fewest networks covering the span,
+// in order, until fn returns false. Addresses are width bits long.
+func summarizeSpan(s span, width int, fn func(addr Uint128, hostBits int) bool) {
+	first := s.first
+	for {
+		// as in summarizeRange4, the network is as large as the trailing zeros of first allow without passing last
+		hostBits := first.TrailingZeros()
+		if hostBits > width {
+			hostBits = width
+		}
+		if size, overflow := s.last.Minus(first).AddOverflow(Uint128{0, 1}); !overflow {
+			if fit := 127 - size.LeadingZeros(); fit < hostBits {
+				hostBits = fit
+			}
+		}
+		if !fn(first, hostBits) {
+			return
+		}
+
+		end := first.Or(Uint128{0, 1}.Lsh(uint(hostBits)).Minus(Uint128{0, 1}))
+		if end == s.last {
+			return
+		}
+		first = end.Add(Uint128{0, 1})
+	}
+}
+
+func ipSeq(c ipCursor) iter.Seq[net.IP] {
+	return func(yield func(net.IP) bool) {
+		for c.Next() {
+			if !yield(c.IP()) {
+				return
+			}
+		}
+	}
+}
+
+func addrSeq(c ipCursor) iter.Seq[netip.Addr] {
+	return func(yield func(netip.Addr) bool) {
+		for c.Next() {
+			if !yield(ipToAddr(c.IP())) {
+				return
+			}
+		}
+	}
+}
+
+func netSeq(c netCursor) iter.Seq[*net.IPNet] {
+	return func(yield func(*net.IPNet) bool) {
+		for c.Next() {
+			if !yield(c.Net()) {
+				return
+			}
+		}
+	}
+}
+
+func prefixSeq(c netCursor) iter.Seq[netip.Prefix] {
+	return func(yield func(netip.Prefix) bool) {
+		for c.Next() {
+			n := c.Net()
+			ones, bits := n.Mask.Size()
+			a, _ := netip.AddrFromSlice(n.IP)
+			if bits == 8*net.IPv4len {
+				a = a.Unmap()
+			}
+			if !yield(netip.PrefixFrom(a, ones)) {
+				return
+			}
+		}
+	}
+}
+
+// ipToAddr returns the netip.Addr for ip, treating IPv4-mapped addresses as IPv4 as net.IP does.
+func ipToAddr(ip net.IP) netip.Addr {
+	if four := ip.To4(); four != nil {
+		return netip.AddrFrom4([4]byte{four[0], four[1], four[2], four[3]})
+	}
+	a, _ := netip.AddrFromSlice(ip)
+	return a
+}
+
+func addrToUint128(a netip.Addr) Uint128 {
+	if a.Is4() {
+		b := a.As4()
+		return Uint128{0, uint64(to32(b[:]))}
+	}
+	b := a.As16()
+	return To128(b[:])
+}
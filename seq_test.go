@@ -0,0 +1,208 @@
+//go:build go1.23
+// +build go1.23
+
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"iter"
+	"net"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+func ExampleIPIter_All() {
+	for ip := range ipx.Hosts(cidr("192.0.2.0/30")).All() {
+		fmt.Println(ip)
+	}
+	// Output:
+	// 192.0.2.1
+	// 192.0.2.2
+}
+
+func ExampleNetIter_Prefixes() {
+	prefixes := slices.Collect(ipx.Split(cidr("2001:db8::/47"), 48).Prefixes())
+	fmt.Println(prefixes)
+	// Output:
+	// [2001:db8::/48 2001:db8:1::/48]
+}
+
+func ExampleCloneIPs() {
+	fmt.Println(slices.Collect(ipx.CloneIPs(ipx.Addresses(cidr("10.0.0.0/31")).All())))
+	// Output:
+	// [10.0.0.0 10.0.0.1]
+}
+
+func TestIPSeqs(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		seq      func() (iter.Seq[net.IP], iter.Seq[netip.Addr])
+		expected string
+	}{
+		{"IterIP", func() (iter.Seq[net.IP], iter.Seq[netip.Addr]) {
+			return ipx.IterIP(net.ParseIP("10.0.0.0"), 2, net.ParseIP("10.0.0.5")).All(),
+				ipx.IterIP(net.ParseIP("10.0.0.0"), 2, net.ParseIP("10.0.0.5")).Addrs()
+		}, "[10.0.0.0 10.0.0.2 10.0.0.4]"},
+		{"Addresses", func() (iter.Seq[net.IP], iter.Seq[netip.Addr]) {
+			return ipx.Addresses(cidr("2001:db8::/126")).All(), ipx.Addresses(cidr("2001:db8::/126")).Addrs()
+		}, "[2001:db8:: 2001:db8::1 2001:db8::2 2001:db8::3]"},
+		{"Hosts", func() (iter.Seq[net.IP], iter.Seq[netip.Addr]) {
+			return ipx.Hosts(cidr("10.0.0.0/29")).All(), ipx.Hosts(cidr("10.0.0.0/29")).Addrs()
+		}, "[10.0.0.1 10.0.0.2 10.0.0.3 10.0.0.4 10.0.0.5 10.0.0.6]"},
+		{"UsableHosts", func() (iter.Seq[net.IP], iter.Seq[netip.Addr]) {
+			return ipx.UsableHosts(cidr("10.0.0.0/29"), ipx.ReserveAWS).All(),
+				ipx.UsableHosts(cidr("10.0.0.0/29"), ipx.ReserveAWS).Addrs()
+		}, "[10.0.0.4 10.0.0.5 10.0.0.6]"},
+		{"AddrPattern", func() (iter.Seq[net.IP], iter.Seq[netip.Addr]) {
+			p, _ := ipx.ParseAddrPattern("10.0-1.0.1-2")
+			return p.Addresses().All(), p.Addresses().Addrs()
+		}, "[10.0.0.1 10.0.0.2 10.1.0.1 10.1.0.2]"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			ipSeq, addrSeq := c.seq()
+			var ips []string
+			for ip := range ipx.CloneIPs(ipSeq) {
+				ips = append(ips, ip.String())
+			}
+			if fmt.Sprint(ips) != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, ips)
+			}
+			if addrs := fmt.Sprint(slices.Collect(addrSeq)); addrs != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, addrs)
+			}
+		})
+	}
+}
+
+func TestIPSeqShared(t *testing.T) {
+	// without cloning, every collected value is the same buffer holding the final address
+	shared := slices.Collect(ipx.Addresses(cidr("10.0.0.0/30")).All())
+	if fmt.Sprint(shared) != "[10.0.0.3 10.0.0.3 10.0.0.3 10.0.0.3]" {
+		t.Fatalf("expected a shared buffer but got %v", shared)
+	}
+	cloned := slices.Collect(ipx.CloneIPs(ipx.Addresses(cidr("10.0.0.0/30")).All()))
+	if fmt.Sprint(cloned) != "[10.0.0.0 10.0.0.1 10.0.0.2 10.0.0.3]" {
+		t.Fatalf("expected copies but got %v", cloned)
+	}
+}
+
+func TestIPSeqBreak(t *testing.T) {
+	ips := ipx.Addresses(cidr("10.0.0.0/29"))
+	for ip := range ips.All() {
+		if ip.Equal(net.ParseIP("10.0.0.2")) {
+			break
+		}
+	}
+	// the iter continues where the loop stopped
+	if !ips.Next() || ips.IP().String() != "10.0.0.3" {
+		t.Fatalf("expected 10.0.0.3 but got %v", ips.IP())
+	}
+}
+
+func TestNetSeqs(t *testing.T) {
+	var nets []string
+	for n := range ipx.CloneNets(ipx.Split(cidr("10.0.0.0/24"), 26).All()) {
+		nets = append(nets, n.String())
+	}
+	if expected := "[10.0.0.0/26 10.0.0.64/26 10.0.0.128/26 10.0.0.192/26]"; fmt.Sprint(nets) != expected {
+		t.Fatalf("expected %v but got %v", expected, nets)
+	}
+
+	prefixes := slices.Collect(ipx.IterNet(cidr("10.0.0.0/24"), 2, cidr("10.0.5.0/24")).Prefixes())
+	if expected := "[10.0.0.0/24 10.0.2.0/24 10.0.4.0/24]"; fmt.Sprint(prefixes) != expected {
+		t.Fatalf("expected %v but got %v", expected, prefixes)
+	}
+
+	var walked []string
+	w := ipx.Walk(cidr("2001:db8::/126"), 126, 128, ipx.WalkPreOrder)
+	for p := range w.Prefixes() {
+		walked = append(walked, p.String())
+		if p.Bits() == 127 {
+			w.SkipChildren()
+		}
+	}
+	if expected := "[2001:db8::/126 2001:db8::/127 2001:db8::2/127]"; fmt.Sprint(walked) != expected {
+		t.Fatalf("expected %v but got %v", expected, walked)
+	}
+}
+
+func TestSummarizeRangeSeq(t *testing.T) {
+	for _, c := range [][2]string{
+		{"192.0.2.0", "192.0.2.130"},
+		{"0.0.0.0", "255.255.255.255"},
+		{"10.0.0.1", "10.0.0.1"},
+		{"10.0.0.7", "10.0.3.200"},
+		{"255.255.255.254", "255.255.255.255"},
+		{"2001:db8::1", "2001:db8::ffff:0"},
+		{"::", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"},
+		{"::1", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe"},
+		{"10.0.0.2", "10.0.0.1"},
+		{"10.0.0.1", "::1"},
+	} {
+		t.Run(fmt.Sprint(c), func(t *testing.T) {
+			first, last := net.ParseIP(c[0]), net.ParseIP(c[1])
+			expected := fmt.Sprint(ipx.SummarizeRange(first, last))
+			if got := fmt.Sprint(slices.Collect(ipx.CloneNets(ipx.SummarizeRangeSeq(first, last)))); got != expected {
+				t.Fatalf("expected %v but got %v", expected, got)
+			}
+
+			var prefixes []string
+			for p := range ipx.SummarizeRangePrefixes(netip.MustParseAddr(c[0]), netip.MustParseAddr(c[1])) {
+				prefixes = append(prefixes, p.String())
+			}
+			if got := fmt.Sprint(prefixes); got != expected {
+				t.Fatalf("expected %v but got %v", expected, got)
+			}
+		})
+	}
+
+	if got := slices.Collect(ipx.SummarizeRangePrefixes(netip.Addr{}, netip.MustParseAddr("::1"))); len(got) != 0 {
+		t.Fatalf("expected no prefixes but got %v", got)
+	}
+	// unlike net.IP, netip treats IPv4-mapped addresses as IPv6
+	got := slices.Collect(ipx.SummarizeRangePrefixes(netip.MustParseAddr("::ffff:10.0.0.0"), netip.MustParseAddr("::ffff:10.0.0.255")))
+	if fmt.Sprint(got) != "[::ffff:10.0.0.0/120]" {
+		t.Fatalf("expected [::ffff:10.0.0.0/120] but got %v", got)
+	}
+}
+
+func TestSeqAllocations(t *testing.T) {
+	ips := ipx.Addresses(cidr("10.0.0.0/24"))
+	addrs := ipx.Addresses(cidr("2001:db8::/120"))
+	nets := ipx.Split(cidr("2001:db8::/32"), 40)
+	for _, c := range []struct {
+		name string
+		fn   func()
+	}{
+		{"All", func() {
+			ips.Reset()
+			for ip := range ips.All() {
+				_ = ip
+			}
+		}},
+		{"Addrs", func() {
+			addrs.Reset()
+			for a := range addrs.Addrs() {
+				_ = a
+			}
+		}},
+		{"Prefixes", func() {
+			nets.Reset()
+			for p := range nets.Prefixes() {
+				_ = p
+			}
+		}},
+		{"SummarizeRangeSeq", func() {
+			for n := range ipx.SummarizeRangeSeq(net.IPv4(10, 0, 0, 1), net.IPv4(10, 255, 255, 254)) {
+				_ = n
+			}
+		}},
+	} {
+		// creating the sequence may allocate, but stepping through its values mustn't
+		if allocs := testing.AllocsPerRun(10, c.fn); allocs > 4 {
+			t.Errorf("expected no allocations per value for %v but got %v in total", c.name, allocs)
+		}
+	}
+}
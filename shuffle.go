@@ -0,0 +1,153 @@
+package ipx
+
+import (
+	"net"
+	"sort"
+)
+
+// shuffleRounds is the number of rounds of the Feistel network permuting indices.
+const shuffleRounds = 4
+
+// ShuffleIter permits iteration over a set of addresses in a pseudorandom order, visiting each exactly once.
+type ShuffleIter struct {
+	spans    []shuffleSpan
+	last     Uint128 // the index of the last address
+	next     Uint128 // the index of the next address to permute
+	done     bool
+	halfBits uint
+	keys     [shuffleRounds]uint64
+
+	ip net.IP
+}
+
+// shuffleSpan is a range of addresses occupying the indices from offset onwards.
+type shuffleSpan struct {
+	span
+	offset Uint128
+	four   bool
+}
+
+// Shuffle returns an iterator over every address of the network in a pseudorandom order determined by seed. The
+// order is a permutation of the addresses' indices within the network computed a step at a time, so the iterator uses
+// constant memory however large the network and can be resumed from a checkpoint. The permutation is not
+// cryptographically secure. Host bits set in ipNet are ignored; an empty iterator is returned for an invalid network.
+func Shuffle(ipNet *net.IPNet, seed int64) *ShuffleIter {
+	s := newShuffleIter(seed)
+	if sp, four, ok := netSpan(ipNet); ok {
+		s.add([]span{sp}, four)
+	}
+	s.Resume(Uint128{})
+	return s
+}
+
+// ShuffleSet returns an iterator over every address of the set in a pseudorandom order, as Shuffle does for a
+// network. IPv4 and IPv6 addresses are shuffled together. A set holding more than 2^128 addresses, which requires
+// nearly every IPv6 address, is too large for the permutation, in which case only its IPv6 addresses are visited.
+func ShuffleSet(set *NetSet, seed int64) *ShuffleIter {
+	s := newShuffleIter(seed)
+	if !s.add(set.four, true) || !s.add(set.six, false) {
+		s.spans = s.spans[:0]
+		s.add(set.six, false)
+	}
+	s.Resume(Uint128{})
+	return s
+}
+
+func newShuffleIter(seed int64) *ShuffleIter {
+	s := &ShuffleIter{ip: make(net.IP, net.IPv6len)}
+	for i := range s.keys {
+		s.keys[i] = mix64(uint64(seed) + uint64(i+1)*0x9e3779b97f4a7c15)
+	}
+	return s
+}
+
+// add appends the spans to those permuted, returning false if the total number of addresses would exceed 2^128.
+func (s *ShuffleIter) add(spans []span, four bool) bool {
+	for _, sp := range spans {
+		var offset Uint128
+		if len(s.spans) > 0 {
+			var overflow bool
+			if offset, overflow = s.last.AddOverflow(Uint128{0, 1}); overflow {
+				return false
+			}
+			if _, overflow = offset.AddOverflow(sp.last.Minus(sp.first)); overflow {
+				return false
+			}
+		}
+		s.spans = append(s.spans, shuffleSpan{span: sp, offset: offset, four: four})
+		s.last = offset.Add(sp.last.Minus(sp.first))
+	}
+	// the Feistel network permutes the smallest domain of an even number of bits holding every index
+	s.halfBits = uint(128-s.last.LeadingZeros()+1) / 2
+	return true
+}
+
+// IP returns the most recent IP; the underlying type may be modified on later calls to `Next`.
+// It does no allocation.
+func (s *ShuffleIter) IP() net.IP {
+	return s.ip
+}
+
+// Next returns true when the underlying pointer has been successfully updated with the next value.
+func (s *ShuffleIter) Next() bool {
+	if s.done {
+		return false
+	}
+
+	// walk the cycle of the permutation until it returns to the indices in use, which it must since the permutation
+	// is a bijection and the starting index is in use
+	x := s.permute(s.next)
+	for x.Cmp(s.last) == 1 {
+		x = s.permute(x)
+	}
+	s.next = s.next.Add(Uint128{0, 1})
+	s.done = s.next.Cmp(s.last) == 1 || s.next.IsZero()
+
+	sp := &s.spans[0]
+	if len(s.spans) > 1 {
+		i := sort.Search(len(s.spans), func(i int) bool {
+			return s.spans[i].offset.Cmp(x) == 1
+		})
+		sp = &s.spans[i-1]
+	}
+	u := sp.first.Add(x.Minus(sp.offset))
+	if sp.four {
+		copy(s.ip, net.IPv4zero)
+		from32(uint32(u.L), s.ip)
+	} else {
+		From128(u, s.ip)
+	}
+	return true
+}
+
+// Checkpoint returns the number of addresses visited so far, from which Resume can continue the iteration. Once
+// every address has been visited, the checkpoint of an iterator over all 2^128 IPv6 addresses wraps to zero.
+func (s *ShuffleIter) Checkpoint() Uint128 {
+	return s.next
+}
+
+// Resume moves the iterator to a checkpoint taken from an iterator over the same addresses with the same seed, such
+// that it visits the addresses that iterator had yet to. Resuming from zero starts the iteration over.
+func (s *ShuffleIter) Resume(checkpoint Uint128) {
+	s.next = checkpoint
+	s.done = len(s.spans) == 0 || checkpoint.Cmp(s.last) == 1
+}
+
+// permute maps an index within the Feistel network's domain to another.
+func (s *ShuffleIter) permute(x Uint128) Uint128 {
+	mask := uint64(1)<<s.halfBits - 1
+	l, r := x.Rsh(s.halfBits).L, x.L&mask
+	for _, k := range s.keys {
+		l, r = r, l^(mix64(r^k)&mask)
+	}
+	return Uint128{0, l}.Lsh(s.halfBits).Or(Uint128{0, r})
+}
+
+// mix64 is the finalizer of the SplitMix64 generator, which scrambles the bits of x.
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	return x ^ x>>31
+}
@@ -0,0 +1,197 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleShuffle() {
+	s := ipx.Shuffle(cidr("192.0.2.0/29"), 42)
+	for s.Next() {
+		fmt.Println(s.IP())
+	}
+	// Output:
+	// 192.0.2.1
+	// 192.0.2.0
+	// 192.0.2.3
+	// 192.0.2.5
+	// 192.0.2.4
+	// 192.0.2.2
+	// 192.0.2.6
+	// 192.0.2.7
+}
+
+func TestShuffle(t *testing.T) {
+	for _, c := range []string{
+		"192.0.2.1/32",
+		"192.0.2.0/31",
+		"192.0.2.0/30",
+		"192.0.2.0/27",
+		"10.0.0.0/20",
+		"255.255.255.0/23",
+		"2001:db8::/128",
+		"2001:db8::/119",
+		"ffff::/112",
+	} {
+		t.Run(c, func(t *testing.T) {
+			n := cidr(c)
+			var expected []string
+			for a := ipx.Addresses(n); a.Next(); {
+				expected = append(expected, a.IP().String())
+			}
+
+			seen := make(map[string]bool)
+			var got []string
+			for s := ipx.Shuffle(n, 1); s.Next(); {
+				if !n.Contains(s.IP()) {
+					t.Fatalf("%v is not in %v", s.IP(), n)
+				}
+				if seen[s.IP().String()] {
+					t.Fatalf("%v visited twice", s.IP())
+				}
+				seen[s.IP().String()] = true
+				got = append(got, s.IP().String())
+			}
+			if len(got) != len(expected) {
+				t.Fatalf("expected %v addresses but got %v", len(expected), len(got))
+			}
+			if len(got) > 8 && fmt.Sprint(got) == fmt.Sprint(expected) {
+				t.Fatalf("expected a shuffled order but got %v", got)
+			}
+		})
+	}
+}
+
+func TestShuffleSeed(t *testing.T) {
+	order := func(seed int64) string {
+		var ips []string
+		for s := ipx.Shuffle(cidr("10.0.0.0/24"), seed); s.Next(); {
+			ips = append(ips, s.IP().String())
+		}
+		return fmt.Sprint(ips)
+	}
+	if order(1) != order(1) {
+		t.Fatal("expected the same seed to give the same order")
+	}
+	if order(1) == order(2) {
+		t.Fatal("expected different seeds to give different orders")
+	}
+}
+
+func TestShuffleResume(t *testing.T) {
+	for _, c := range []string{"10.0.0.0/22", "2001:db8::/118"} {
+		t.Run(c, func(t *testing.T) {
+			var all []string
+			for s := ipx.Shuffle(cidr(c), 7); s.Next(); {
+				all = append(all, s.IP().String())
+			}
+
+			s := ipx.Shuffle(cidr(c), 7)
+			for i := 0; i < 100; i++ {
+				s.Next()
+			}
+			checkpoint := s.Checkpoint()
+			if checkpoint != (ipx.Uint128{L: 100}) {
+				t.Fatalf("expected checkpoint 100 but got %v", checkpoint)
+			}
+
+			resumed := ipx.Shuffle(cidr(c), 7)
+			resumed.Resume(checkpoint)
+			var rest []string
+			for resumed.Next() {
+				rest = append(rest, resumed.IP().String())
+			}
+			if fmt.Sprint(rest) != fmt.Sprint(all[100:]) {
+				t.Fatalf("expected %v but got %v", all[100:], rest)
+			}
+
+			resumed.Resume(ipx.Uint128{})
+			if !resumed.Next() || resumed.IP().String() != all[0] {
+				t.Fatalf("expected to start over at %v but got %v", all[0], resumed.IP())
+			}
+		})
+	}
+}
+
+func TestShuffleHuge(t *testing.T) {
+	for _, c := range []string{"::/0", "2001:db8::/33", "2001:db8::/64", "0.0.0.0/0"} {
+		t.Run(c, func(t *testing.T) {
+			n := cidr(c)
+			ones, bits := n.Mask.Size()
+			// resume close to the end to check the iteration finishes
+			checkpoint := ipx.Uint128{L: 1}.Lsh(uint(bits - ones)).Minus(ipx.Uint128{L: 5})
+
+			s := ipx.Shuffle(n, 3)
+			seen := make(map[string]bool)
+			for i := 0; i < 1000 && s.Next(); i++ {
+				if !n.Contains(s.IP()) || seen[s.IP().String()] {
+					t.Fatalf("unexpected %v", s.IP())
+				}
+				seen[s.IP().String()] = true
+			}
+
+			s.Resume(checkpoint)
+			count := 0
+			for s.Next() {
+				if !n.Contains(s.IP()) {
+					t.Fatalf("%v is not in %v", s.IP(), n)
+				}
+				count++
+			}
+			if count != 5 {
+				t.Fatalf("expected 5 addresses after resuming but got %v", count)
+			}
+		})
+	}
+}
+
+func TestShuffleSet(t *testing.T) {
+	nets := cidrs([]string{"10.0.0.0/30", "10.0.1.0/31", "192.0.2.7/32", "2001:db8::/126", "2001:db8::8/127"})
+	set := ipx.NewNetSet(nets)
+
+	expected := make(map[string]bool)
+	for _, n := range nets {
+		for a := ipx.Addresses(n); a.Next(); {
+			expected[a.IP().String()] = true
+		}
+	}
+
+	got := make(map[string]bool)
+	for s := ipx.ShuffleSet(set, 5); s.Next(); {
+		if got[s.IP().String()] {
+			t.Fatalf("%v visited twice", s.IP())
+		}
+		got[s.IP().String()] = true
+	}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v but got %v", expected, got)
+	}
+}
+
+func TestShuffleSetTooLarge(t *testing.T) {
+	set := ipx.NewNetSet(cidrs([]string{"10.0.0.0/8", "::/1", "8000::/1"}))
+	s := ipx.ShuffleSet(set, 5)
+	for i := 0; i < 100 && s.Next(); i++ {
+		if s.IP().To4() != nil {
+			t.Fatalf("expected only IPv6 addresses but got %v", s.IP())
+		}
+	}
+}
+
+func TestShuffleEmpty(t *testing.T) {
+	if s := ipx.Shuffle(&net.IPNet{IP: net.IPv4zero, Mask: net.IPMask{0xff, 0, 0xff, 0}}, 1); s.Next() {
+		t.Fatalf("expected no addresses but got %v", s.IP())
+	}
+	if s := ipx.ShuffleSet(ipx.NewNetSet(nil), 1); s.Next() {
+		t.Fatalf("expected no addresses but got %v", s.IP())
+	}
+}
+
+func TestShuffleAllocations(t *testing.T) {
+	s := ipx.ShuffleSet(ipx.NewNetSet(cidrs([]string{"10.0.0.0/8", "192.168.0.0/16", "2001:db8::/32"})), 1)
+	if allocs := testing.AllocsPerRun(1000, func() { s.Next() }); allocs != 0 {
+		t.Fatalf("expected no allocations but got %v", allocs)
+	}
+}
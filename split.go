@@ -4,21 +4,29 @@ import (
 	"net"
 )
 
-// Split splits a subnet into smaller subnets according to the new prefix provided.
+// Split splits a subnet into smaller subnets according to the new prefix provided. Host bits set in ipNet are
+// ignored.
 func Split(ipNet *net.IPNet, newPrefix int) *NetIter {
 	ones, bits := ipNet.Mask.Size()
 	if ones > newPrefix || newPrefix > bits {
 		return new(NetIter)
 	}
+	if newPrefix == 0 {
+		// the step would overflow, but the only subnet is the whole address space
+		if ipNet.IP.To4() != nil {
+			return &NetIter{ips: *iterIPv4(0, 1, 0), net: &net.IPNet{Mask: net.CIDRMask(0, bits)}}
+		}
+		return &NetIter{ips: *iterIPv6(Uint128{}, Uint128{0, 1}, Uint128{}), net: &net.IPNet{Mask: net.CIDRMask(0, bits)}}
+	}
 	if ipNet.IP.To4() != nil {
-		ip := to32(ipNet.IP)
+		ip := to32(ipNet.IP) & to32(ipNet.Mask)
 		return &NetIter{
 			ips: *iterIPv4(ip, 1<<(bits-newPrefix), ip|(1<<(bits-ones)-1)),
 			net: &net.IPNet{Mask: net.CIDRMask(newPrefix, bits)},
 		}
 	}
 
-	ip := To128(ipNet.IP)
+	ip := To128(ipNet.IP).And(To128(ipNet.Mask))
 
 	incr := Uint128{0, 1}.Lsh(uint(bits - newPrefix))
 
@@ -28,51 +36,66 @@ func Split(ipNet *net.IPNet, newPrefix int) *NetIter {
 		Or(ip)
 
 	return &NetIter{
-		*iterIPv6(ip, incr, broadCast),
-		&net.IPNet{Mask: net.CIDRMask(newPrefix, bits)},
+		ips: *iterIPv6(ip, incr, broadCast),
+		net: &net.IPNet{Mask: net.CIDRMask(newPrefix, bits)},
 	}
 }
 
-// Addresses returns all of the addresses within a network.
+// Addresses returns all of the addresses within a network. Host bits set in ipNet are ignored.
 func Addresses(ipNet *net.IPNet) *IPIter {
-	ones, bits := ipNet.Mask.Size()
-	if ipNet.IP.To4() != nil {
-		ip := to32(ipNet.IP)
-		return iterIPv4(
-			ip,
-			1,
-			ip+(1<<(bits-ones)),
-		)
-	}
-	ip := To128(ipNet.IP)
-	return iterIPv6(
-		ip,
-		Uint128{0, 1},
-		ip.Add(Uint128{0, 1}.Lsh(uint(bits-ones))),
-	)
+	return HostsWithPolicy(ipNet, HostsAll)
 }
 
-// Hosts returns all of the usable addresses within a network except the network itself address and the broadcast address
+// HostsPolicy selects which addresses of a network are treated as usable hosts.
+type HostsPolicy uint8
+
+const (
+	// HostsDefault excludes the network and broadcast addresses of IPv4 networks and only the subnet-router anycast
+	// address, which is the network address, of IPv6 networks, like Python's ipaddress library. Point-to-point
+	// networks (/31 and /127) have both of their addresses as hosts, per RFC 3021 and RFC 6164, and single address
+	// networks have that address.
+	HostsDefault HostsPolicy = iota
+	// HostsReserveFirstLast excludes the first and last addresses of networks of both IP versions. Point-to-point and
+	// single address networks are treated as with HostsDefault.
+	HostsReserveFirstLast
+	// HostsAll treats every address as a host.
+	HostsAll
+)
+
+// Hosts returns all of the usable addresses within a network according to HostsDefault: for IPv4 all but the network
+// and broadcast addresses, for IPv6 all but the subnet-router anycast address, and every address of point-to-point
+// and single address networks. Host bits set in ipNet are ignored.
 func Hosts(ipNet *net.IPNet) *IPIter {
+	return HostsWithPolicy(ipNet, HostsDefault)
+}
+
+// HostsWithPolicy returns the usable addresses within a network according to the policy. Host bits set in ipNet are
+// ignored.
+func HostsWithPolicy(ipNet *net.IPNet, policy HostsPolicy) *IPIter {
 	ones, bits := ipNet.Mask.Size()
-	if ipNet.IP.To4() != nil {
-		ip := to32(ipNet.IP) + 1
+	four := ipNet.IP.To4() != nil
+
+	var reserveFirst, reserveLast uint32
+	if policy != HostsAll && bits-ones > 1 {
+		reserveFirst = 1
+		if four || policy == HostsReserveFirstLast {
+			reserveLast = 1
+		}
+	}
+
+	if four {
+		ip := to32(ipNet.IP) & to32(ipNet.Mask)
 		return iterIPv4(
-			ip,
+			ip+reserveFirst,
 			1,
-			ip+(1<<(bits-ones))-2,
+			ip|^to32(ipNet.Mask)-reserveLast,
 		)
 	}
 
-	ip := To128(ipNet.IP).Add(Uint128{0, 1})
-
-	addend := Uint128{0, 1}.
-		Lsh(uint(bits - ones)).
-		Minus(Uint128{0, 2})
-
+	ip := To128(ipNet.IP).And(To128(ipNet.Mask))
 	return iterIPv6(
-		ip,
+		ip.Add(Uint128{0, uint64(reserveFirst)}),
 		Uint128{0, 1},
-		ip.Add(addend),
+		ip.Or(To128(ipNet.Mask).Not()).Minus(Uint128{0, uint64(reserveLast)}),
 	)
 }
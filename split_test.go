@@ -19,6 +19,18 @@ func TestSplit(t *testing.T) {
 			24,
 			[]string{"10.0.0.0/24"},
 		},
+		{
+			"no-op everything",
+			"0.0.0.0/0",
+			0,
+			[]string{"0.0.0.0/0"},
+		},
+		{
+			"no-op everything v6",
+			"::/0",
+			0,
+			[]string{"::/0"},
+		},
 		{
 			"invalid prefix",
 			"10.0.0.0/24",
@@ -71,7 +83,7 @@ func ExampleSplit() {
 	// 10.0.0.192/26
 }
 
-func ExampleSplit_IP6() {
+func ExampleSplit_ip6() {
 	c := cidr("::/24")
 	split := ipx.Split(c, 26)
 	for split.Next() {
@@ -156,6 +168,7 @@ func TestAddresses(t *testing.T) {
 			},
 		},
 		{"ipv6 128", "1bc1:6d67:4ec8::3/128", []string{"1bc1:6d67:4ec8::3"}},
+		{"ipv4 top", "255.255.255.254/31", []string{"255.255.255.254", "255.255.255.255"}},
 	} {
 		t.Run(c.name, func(t *testing.T) {
 			_, ipN, _ := net.ParseCIDR(c.net)
@@ -230,7 +243,7 @@ func ExampleHosts() {
 	// 10.0.0.6
 }
 
-func ExampleHosts_IP6() {
+func ExampleHosts_ip6() {
 	c := cidr("::/125")
 	hosts := ipx.Hosts(c)
 	for hosts.Next() {
@@ -243,6 +256,50 @@ func ExampleHosts_IP6() {
 	// ::4
 	// ::5
 	// ::6
+	// ::7
+}
+
+func TestHosts(t *testing.T) {
+	for _, c := range []struct {
+		name, net string
+		policy    ipx.HostsPolicy
+		expected  []string
+	}{
+		{"ipv4 30", "10.0.0.0/30", ipx.HostsDefault, []string{"10.0.0.1", "10.0.0.2"}},
+		{"ipv4 31", "10.0.0.0/31", ipx.HostsDefault, []string{"10.0.0.0", "10.0.0.1"}},
+		{"ipv4 32", "10.0.0.3/32", ipx.HostsDefault, []string{"10.0.0.3"}},
+		{"ipv4 top", "255.255.255.252/30", ipx.HostsDefault, []string{"255.255.255.253", "255.255.255.254"}},
+		{"ipv6 126", "2001:db8::/126", ipx.HostsDefault, []string{"2001:db8::1", "2001:db8::2", "2001:db8::3"}},
+		{"ipv6 127", "2001:db8::/127", ipx.HostsDefault, []string{"2001:db8::", "2001:db8::1"}},
+		{"ipv6 128", "2001:db8::3/128", ipx.HostsDefault, []string{"2001:db8::3"}},
+		{"ipv6 top", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe/127", ipx.HostsDefault, []string{
+			"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff",
+		}},
+
+		{"reserve ipv4 30", "10.0.0.0/30", ipx.HostsReserveFirstLast, []string{"10.0.0.1", "10.0.0.2"}},
+		{"reserve ipv6 126", "2001:db8::/126", ipx.HostsReserveFirstLast, []string{"2001:db8::1", "2001:db8::2"}},
+		{"reserve ipv6 127", "2001:db8::/127", ipx.HostsReserveFirstLast, []string{"2001:db8::", "2001:db8::1"}},
+
+		{"all ipv4 30", "10.0.0.0/30", ipx.HostsAll, []string{"10.0.0.0", "10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+		{"all ipv6 127", "2001:db8::/127", ipx.HostsAll, []string{"2001:db8::", "2001:db8::1"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var ips []string
+			iter := ipx.HostsWithPolicy(cidr(c.net), c.policy)
+			for iter.Next() {
+				ips = append(ips, iter.IP().String())
+			}
+
+			if len(c.expected) != len(ips) {
+				t.Fatalf("expected %v addresses but got %v: %v", len(c.expected), len(ips), ips)
+			}
+			for i := range ips {
+				if ips[i] != c.expected[i] {
+					t.Errorf("expected %v at position %d but got %v", c.expected[i], i, ips[i])
+				}
+			}
+		})
+	}
 }
 
 func BenchmarkHosts(b *testing.B) {
@@ -260,8 +317,8 @@ func BenchmarkHosts(b *testing.B) {
 		{
 			"ipv6",
 			[]string{
-				"::/126", // 4-2
-				"::/120", // 256-2
+				"::/126", // 4-1
+				"::/120", // 256-1
 			},
 		},
 	} {
@@ -53,12 +53,12 @@ func summarizeRange4(first, last uint32) (nets []*net.IPNet) {
 func summarizeRange6(first, last Uint128) (nets []*net.IPNet) {
 	for first.Cmp(last) != 1 {
 		bits := 128
-		if trailingZeros := trailingZeros128(first); trailingZeros < bits {
+		if trailingZeros := first.TrailingZeros(); trailingZeros < bits {
 			bits = trailingZeros
 		}
 		// check extremes to make sure no overflow
 		if first.Cmp(Uint128{0, 0}) != 0 || last.Cmp(Uint128{maxUint64, maxUint64}) != 0 {
-			if diffBits := 127 - leadingZeros128(last.Minus(first).Add(Uint128{0, 1})); diffBits < bits {
+			if diffBits := 127 - last.Minus(first).Add(Uint128{0, 1}).LeadingZeros(); diffBits < bits {
 				bits = diffBits
 			}
 		}
@@ -76,22 +76,6 @@ func summarizeRange6(first, last Uint128) (nets []*net.IPNet) {
 	return
 }
 
-func trailingZeros128(i Uint128) int {
-	trailingZeros := b.TrailingZeros64(i.L)
-	if trailingZeros == 64 {
-		trailingZeros += b.TrailingZeros64(i.H)
-	}
-	return trailingZeros
-}
-
-func leadingZeros128(i Uint128) int {
-	leadingZeros := b.LeadingZeros64(i.H)
-	if leadingZeros == 64 {
-		leadingZeros += b.LeadingZeros64(i.L)
-	}
-	return leadingZeros
-}
-
 func allFF(b []byte) bool {
 	for _, c := range b {
 		if c != 0xff {
@@ -16,7 +16,7 @@ func ExampleSummarizeRange() {
 	// [192.0.2.0/25 192.0.2.128/31 192.0.2.130/32]
 }
 
-func ExampleSummarizeRange_IP6() {
+func ExampleSummarizeRange_ip6() {
 	fmt.Println(ipx.SummarizeRange(
 		net.ParseIP("::"),
 		net.ParseIP("ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff"),
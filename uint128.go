@@ -1,8 +1,16 @@
 package ipx
 
-import "encoding/binary"
+import (
+	"encoding/binary"
+	"errors"
+	"math/bits"
+)
 
 // largely cribbed from https://github.com/davidminor/uint128 and https://github.com/lukechampine/uint128
+
+// Uint128 is an unsigned 128 bit integer, used for IPv6 addresses and for counts of addresses. Counts which would be
+// 2^128, such as the number of addresses in ::/0, don't fit; the functions returning counts report them as the maximum
+// value, 2^128-1, instead, which makes NumAddresses and NumHosts equal for ::/0 though Hosts excludes ::.
 type Uint128 struct {
 	H, L uint64
 }
@@ -85,6 +93,175 @@ func (u Uint128) Not() Uint128 {
 	return Uint128{^u.H, ^u.L}
 }
 
+// Xor returns the bitwise exclusive or of u and other.
+func (u Uint128) Xor(other Uint128) Uint128 {
+	u.H ^= other.H
+	u.L ^= other.L
+	return u
+}
+
+// AndNot returns u with every bit set in other cleared.
+func (u Uint128) AndNot(other Uint128) Uint128 {
+	u.H &^= other.H
+	u.L &^= other.L
+	return u
+}
+
+// IsZero returns whether u is zero.
+func (u Uint128) IsZero() bool {
+	return u.H == 0 && u.L == 0
+}
+
+// AddOverflow returns u + addend and whether the sum wrapped past the maximum value.
+func (u Uint128) AddOverflow(addend Uint128) (Uint128, bool) {
+	var carry uint64
+	u.L, carry = bits.Add64(u.L, addend.L, 0)
+	u.H, carry = bits.Add64(u.H, addend.H, carry)
+	return u, carry != 0
+}
+
+// SubUnderflow returns u - subtrahend and whether the difference wrapped below zero.
+func (u Uint128) SubUnderflow(subtrahend Uint128) (Uint128, bool) {
+	var borrow uint64
+	u.L, borrow = bits.Sub64(u.L, subtrahend.L, 0)
+	u.H, borrow = bits.Sub64(u.H, subtrahend.H, borrow)
+	return u, borrow != 0
+}
+
+// Mul returns u * multiplier, wrapping on overflow.
+func (u Uint128) Mul(multiplier Uint128) Uint128 {
+	h, l := bits.Mul64(u.L, multiplier.L)
+	h += u.H*multiplier.L + u.L*multiplier.H
+	return Uint128{h, l}
+}
+
+// Mul64 returns u * multiplier, wrapping on overflow.
+func (u Uint128) Mul64(multiplier uint64) Uint128 {
+	h, l := bits.Mul64(u.L, multiplier)
+	h += u.H * multiplier
+	return Uint128{h, l}
+}
+
+// Mul64Overflow returns u * multiplier and whether the product overflowed.
+func (u Uint128) Mul64Overflow(multiplier uint64) (Uint128, bool) {
+	h, l := bits.Mul64(u.L, multiplier)
+	hh, hl := bits.Mul64(u.H, multiplier)
+	h, carry := bits.Add64(h, hl, 0)
+	return Uint128{h, l}, hh != 0 || carry != 0
+}
+
+// QuoRem returns the quotient and remainder of u / divisor. It panics if divisor is zero.
+func (u Uint128) QuoRem(divisor Uint128) (q, r Uint128) {
+	if divisor.H == 0 {
+		var r64 uint64
+		q, r64 = u.QuoRem64(divisor.L)
+		return q, Uint128{0, r64}
+	}
+
+	// normalize the divisor so its top bit is set, which yields a trial quotient off by at most one
+	n := uint(bits.LeadingZeros64(divisor.H))
+	d := divisor.Lsh(n)
+	s := u.Rsh(1)
+	tq, _ := bits.Div64(s.H, s.L, d.H)
+	tq >>= 63 - n
+	if tq != 0 {
+		tq--
+	}
+
+	q = Uint128{0, tq}
+	r = u.Minus(divisor.Mul64(tq))
+	if r.Cmp(divisor) != -1 {
+		q = q.Add(Uint128{0, 1})
+		r = r.Minus(divisor)
+	}
+	return q, r
+}
+
+// QuoRem64 returns the quotient and remainder of u / divisor. It panics if divisor is zero.
+func (u Uint128) QuoRem64(divisor uint64) (q Uint128, r uint64) {
+	if u.H < divisor {
+		q.L, r = bits.Div64(u.H, u.L, divisor)
+		return q, r
+	}
+	q.H, r = bits.Div64(0, u.H, divisor)
+	q.L, r = bits.Div64(r, u.L, divisor)
+	return q, r
+}
+
+// Div returns u / divisor. It panics if divisor is zero.
+func (u Uint128) Div(divisor Uint128) Uint128 {
+	q, _ := u.QuoRem(divisor)
+	return q
+}
+
+// Div64 returns u / divisor. It panics if divisor is zero.
+func (u Uint128) Div64(divisor uint64) Uint128 {
+	q, _ := u.QuoRem64(divisor)
+	return q
+}
+
+// Mod returns u % divisor. It panics if divisor is zero.
+func (u Uint128) Mod(divisor Uint128) Uint128 {
+	_, r := u.QuoRem(divisor)
+	return r
+}
+
+// Mod64 returns u % divisor. It panics if divisor is zero.
+func (u Uint128) Mod64(divisor uint64) uint64 {
+	_, r := u.QuoRem64(divisor)
+	return r
+}
+
+// LeadingZeros returns the number of leading zero bits in u; the result is 128 for u == 0.
+func (u Uint128) LeadingZeros() int {
+	if u.H != 0 {
+		return bits.LeadingZeros64(u.H)
+	}
+	return 64 + bits.LeadingZeros64(u.L)
+}
+
+// TrailingZeros returns the number of trailing zero bits in u; the result is 128 for u == 0.
+func (u Uint128) TrailingZeros() int {
+	if u.L != 0 {
+		return bits.TrailingZeros64(u.L)
+	}
+	return 64 + bits.TrailingZeros64(u.H)
+}
+
+// OnesCount returns the number of one bits in u.
+func (u Uint128) OnesCount() int {
+	return bits.OnesCount64(u.H) + bits.OnesCount64(u.L)
+}
+
+// Bit returns the value of the i'th bit of u, counting from the least significant bit. It panics if i is not in
+// [0, 128).
+func (u Uint128) Bit(i int) uint {
+	switch {
+	case i < 0 || i >= 128:
+		panic(errors.New("bit index out of range"))
+	case i >= 64:
+		return uint(u.H>>uint(i-64)) & 1
+	default:
+		return uint(u.L>>uint(i)) & 1
+	}
+}
+
+// SetBit returns u with the i'th bit, counting from the least significant bit, set to b, which must be 0 or 1. It
+// panics if i is not in [0, 128).
+func (u Uint128) SetBit(i int, b uint) Uint128 {
+	if i < 0 || i >= 128 {
+		panic(errors.New("bit index out of range"))
+	}
+	if b&^1 != 0 {
+		panic(errors.New("bit value must be 0 or 1"))
+	}
+	m := Uint128{0, 1}.Lsh(uint(i))
+	if b == 0 {
+		return u.AndNot(m)
+	}
+	return u.Or(m)
+}
+
 // To128 returns Uint128 for a given bytes
 func To128(bytes []byte) Uint128 {
 	return Uint128{binary.BigEndian.Uint64(bytes[:8]), binary.BigEndian.Uint64(bytes[8:])}
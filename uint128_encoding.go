@@ -0,0 +1,174 @@
+package ipx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// largest power of ten which fits in a uint64; used to peel off decimal digits 19 at a time
+	maxPow10Uint64 = 10000000000000000000
+	pow10Digits    = 19
+)
+
+// String returns the decimal representation of u.
+func (u Uint128) String() string {
+	if u.H == 0 {
+		return strconv.FormatUint(u.L, 10)
+	}
+	q, r := u.QuoRem64(maxPow10Uint64)
+	digits := strconv.FormatUint(r, 10)
+	return q.String() + strings.Repeat("0", pow10Digits-len(digits)) + digits
+}
+
+// Format implements fmt.Formatter, supporting the same verbs and flags as big.Int: 'b', 'o', 'O', 'd', 'x', 'X',
+// 's' and 'v'.
+func (u Uint128) Format(s fmt.State, ch rune) {
+	u.Big().Format(s, ch)
+}
+
+// Big returns u as a big.Int.
+func (u Uint128) Big() *big.Int {
+	i := new(big.Int).SetUint64(u.H)
+	i.Lsh(i, 64)
+	return i.Or(i, new(big.Int).SetUint64(u.L))
+}
+
+// FromBig returns the Uint128 for the provided big.Int. It returns an error if i is negative or does not fit in 128
+// bits.
+func FromBig(i *big.Int) (Uint128, error) {
+	if i.Sign() < 0 {
+		return Uint128{}, errors.New("value must not be negative")
+	}
+	if i.BitLen() > 128 {
+		return Uint128{}, errors.New("value overflows 128 bits")
+	}
+	var bytes [16]byte
+	b := i.Bytes()
+	copy(bytes[len(bytes)-len(b):], b)
+	return To128(bytes[:]), nil
+}
+
+// ParseUint128 interprets s in the given base (0, or 2 to 36) and returns the corresponding value. If base is 0, the
+// base is implied by the string's prefix: "0b" for 2, "0o" or "0" for 8, "0x" for 16 and 10 otherwise. Errors are of
+// type *strconv.NumError, as with strconv.ParseUint.
+func ParseUint128(s string, base int) (Uint128, error) {
+	const fnParseUint128 = "ParseUint128"
+
+	if s == "" {
+		return Uint128{}, &strconv.NumError{Func: fnParseUint128, Num: s, Err: strconv.ErrSyntax}
+	}
+
+	digits := s
+	switch {
+	case base == 0:
+		base = 10
+		if digits[0] == '0' {
+			switch {
+			case len(digits) >= 3 && lowerASCII(digits[1]) == 'b':
+				base, digits = 2, digits[2:]
+			case len(digits) >= 3 && lowerASCII(digits[1]) == 'o':
+				base, digits = 8, digits[2:]
+			case len(digits) >= 3 && lowerASCII(digits[1]) == 'x':
+				base, digits = 16, digits[2:]
+			default:
+				base, digits = 8, digits[1:]
+				if digits == "" {
+					return Uint128{}, nil
+				}
+			}
+		}
+	case base < 2 || base > 36:
+		return Uint128{}, &strconv.NumError{
+			Func: fnParseUint128,
+			Num:  s,
+			Err:  errors.New("invalid base " + strconv.Itoa(base)),
+		}
+	}
+
+	cutoff := Uint128{maxUint64, maxUint64}.Div64(uint64(base))
+
+	var u Uint128
+	for i := 0; i < len(digits); i++ {
+		var d byte
+		switch c := digits[i]; {
+		case '0' <= c && c <= '9':
+			d = c - '0'
+		case 'a' <= lowerASCII(c) && lowerASCII(c) <= 'z':
+			d = lowerASCII(c) - 'a' + 10
+		default:
+			return Uint128{}, &strconv.NumError{Func: fnParseUint128, Num: s, Err: strconv.ErrSyntax}
+		}
+		if int(d) >= base {
+			return Uint128{}, &strconv.NumError{Func: fnParseUint128, Num: s, Err: strconv.ErrSyntax}
+		}
+
+		if u.Cmp(cutoff) == 1 {
+			return Uint128{maxUint64, maxUint64}, &strconv.NumError{Func: fnParseUint128, Num: s, Err: strconv.ErrRange}
+		}
+		var overflow bool
+		if u, overflow = u.Mul64(uint64(base)).AddOverflow(Uint128{0, uint64(d)}); overflow {
+			return Uint128{maxUint64, maxUint64}, &strconv.NumError{Func: fnParseUint128, Num: s, Err: strconv.ErrRange}
+		}
+	}
+	return u, nil
+}
+
+func lowerASCII(c byte) byte {
+	return c | ('x' - 'X')
+}
+
+// MarshalText implements encoding.TextMarshaler using the decimal representation.
+func (u Uint128) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting the decimal representation.
+func (u *Uint128) UnmarshalText(text []byte) error {
+	v, err := ParseUint128(string(text), 10)
+	if err != nil {
+		return err
+	}
+	*u = v
+	return nil
+}
+
+// MarshalJSON implements json.Marshaler. The value is encoded as a decimal string, since most JSON consumers cannot
+// represent integers beyond 2^53 exactly.
+func (u Uint128) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(u.String())), nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting either a decimal string or a bare JSON number.
+func (u *Uint128) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		var err error
+		if s, err = strconv.Unquote(s); err != nil {
+			return err
+		}
+	}
+	return u.UnmarshalText([]byte(s))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler as 16 big-endian bytes.
+func (u Uint128) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 16)
+	From128(u, b)
+	return b, nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, expecting 16 big-endian bytes.
+func (u *Uint128) UnmarshalBinary(data []byte) error {
+	if len(data) != 16 {
+		return errors.New("binary representation must be 16 bytes")
+	}
+	*u = To128(data)
+	return nil
+}
@@ -0,0 +1,166 @@
+package ipx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"testing"
+)
+
+func TestUint128String(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		u        Uint128
+		expected string
+	}{
+		{"zero", Uint128{0, 0}, "0"},
+		{"low", Uint128{0, maxUint64}, "18446744073709551615"},
+		{"high", Uint128{1, 0}, "18446744073709551616"},
+		{"power of ten", Uint128{0, 1}.Mul64(maxPow10Uint64).Mul64(10), "100000000000000000000"},
+		{"max", Uint128{maxUint64, maxUint64}, "340282366920938463463374607431768211455"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.u.String(); got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+			if got := c.u.Big().String(); got != c.expected {
+				t.Fatalf("expected big %v but got %v", c.expected, got)
+			}
+			parsed, err := ParseUint128(c.expected, 10)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if parsed != c.u {
+				t.Fatalf("expected to parse %v but got %v", c.u, parsed)
+			}
+		})
+	}
+}
+
+func TestUint128Format(t *testing.T) {
+	u := Uint128{1, 0xff}
+	for _, c := range []struct {
+		format, expected string
+	}{
+		{"%v", "18446744073709551871"},
+		{"%d", "18446744073709551871"},
+		{"%x", "100000000000000ff"},
+		{"%#X", "0X100000000000000FF"},
+		{"%b", "1" + fmt.Sprintf("%064b", 0xff)},
+		{"%40d", "                    18446744073709551871"},
+	} {
+		t.Run(c.format, func(t *testing.T) {
+			if got := fmt.Sprintf(c.format, u); got != c.expected {
+				t.Fatalf("expected %q but got %q", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestParseUint128(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		s        string
+		base     int
+		expected Uint128
+		err      error
+	}{
+		{"decimal", "12345", 10, Uint128{0, 12345}, nil},
+		{"hex", "ffffffffffffffffffffffffffffffff", 16, Uint128{maxUint64, maxUint64}, nil},
+		{"binary", "101", 2, Uint128{0, 5}, nil},
+		{"base 36", "z", 36, Uint128{0, 35}, nil},
+		{"implied hex", "0x10000000000000000", 0, Uint128{1, 0}, nil},
+		{"implied octal", "017", 0, Uint128{0, 15}, nil},
+		{"implied octal prefix", "0o17", 0, Uint128{0, 15}, nil},
+		{"implied binary", "0b11", 0, Uint128{0, 3}, nil},
+		{"implied decimal", "99", 0, Uint128{0, 99}, nil},
+		{"implied zero", "0", 0, Uint128{}, nil},
+		{"empty", "", 10, Uint128{}, strconv.ErrSyntax},
+		{"bad digit", "12a", 10, Uint128{}, strconv.ErrSyntax},
+		{"control byte", "1\x102", 10, Uint128{}, strconv.ErrSyntax},
+		{"upper case", "FF", 16, Uint128{0, 255}, nil},
+		{"negative", "-1", 10, Uint128{}, strconv.ErrSyntax},
+		{"overflow", "340282366920938463463374607431768211456", 10, Uint128{maxUint64, maxUint64}, strconv.ErrRange},
+		{"overflow hex", "100000000000000000000000000000000", 16, Uint128{maxUint64, maxUint64}, strconv.ErrRange},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got, err := ParseUint128(c.s, c.base)
+			if c.err != nil {
+				if !errors.Is(err, c.err) {
+					t.Fatalf("expected error %v but got %v", c.err, err)
+				}
+			} else if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+
+	if _, err := ParseUint128("1", 37); err == nil {
+		t.Fatal("expected error for invalid base")
+	}
+}
+
+func TestFromBig(t *testing.T) {
+	max := Uint128{maxUint64, maxUint64}
+	got, err := FromBig(max.Big())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != max {
+		t.Fatalf("expected %v but got %v", max, got)
+	}
+
+	if _, err := FromBig(new(big.Int).Add(max.Big(), big.NewInt(1))); err == nil {
+		t.Fatal("expected overflow error")
+	}
+	if _, err := FromBig(big.NewInt(-1)); err == nil {
+		t.Fatal("expected negative error")
+	}
+}
+
+func TestUint128Marshal(t *testing.T) {
+	type doc struct {
+		Count Uint128 `json:"count"`
+	}
+	d := doc{Uint128{1, 0}}
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := `{"count":"18446744073709551616"}`; string(b) != expected {
+		t.Fatalf("expected %v but got %v", expected, string(b))
+	}
+
+	var decoded doc
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded != d {
+		t.Fatalf("expected %v but got %v", d, decoded)
+	}
+
+	if err := json.Unmarshal([]byte(`{"count":42}`), &decoded); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if decoded.Count != (Uint128{0, 42}) {
+		t.Fatalf("expected 42 but got %v", decoded.Count)
+	}
+
+	bin, _ := d.Count.MarshalBinary()
+	var fromBin Uint128
+	if err := fromBin.UnmarshalBinary(bin); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if fromBin != d.Count {
+		t.Fatalf("expected %v but got %v", d.Count, fromBin)
+	}
+	if err := fromBin.UnmarshalBinary(bin[:4]); err == nil {
+		t.Fatal("expected error for short input")
+	}
+}
@@ -121,6 +121,70 @@ func TestUint128(t *testing.T) {
 			Uint128{0, maxUint64}.Not(),
 			b().Lsh(maxU64B, 64),
 		},
+
+		{
+			"xor",
+			Uint128{maxUint64, 0}.Xor(Uint128{maxUint64, maxUint64}),
+			maxU64B,
+		},
+		{
+			"and not",
+			Uint128{maxUint64, maxUint64}.AndNot(Uint128{maxUint64, 0}),
+			maxU64B,
+		},
+
+		{
+			"mul",
+			Uint128{0, maxUint64}.Mul(Uint128{0, maxUint64}),
+			b().Mul(maxU64B, maxU64B),
+		},
+		{
+			"mul overflow",
+			Uint128{maxUint64, maxUint64}.Mul(Uint128{0, 2}),
+			b().Sub(maxU128B, big.NewInt(1)),
+		},
+		{
+			"mul64",
+			Uint128{1, 1}.Mul64(3),
+			b().Add(b().Lsh(big.NewInt(3), 64), big.NewInt(3)),
+		},
+
+		{
+			"div",
+			Uint128{maxUint64, maxUint64}.Div(Uint128{1, 0}),
+			maxU64B,
+		},
+		{
+			"div larger divisor",
+			Uint128{1, 0}.Div(Uint128{2, 0}),
+			big.NewInt(0),
+		},
+		{
+			"div64",
+			Uint128{1, 0}.Div64(2),
+			b().Lsh(big.NewInt(1), 63),
+		},
+		{
+			"mod",
+			Uint128{maxUint64, maxUint64}.Mod(Uint128{1, 0}),
+			maxU64B,
+		},
+		{
+			"mod64",
+			Uint128{0, Uint128{0, 10}.Mod64(3)},
+			big.NewInt(1),
+		},
+
+		{
+			"set bit",
+			Uint128{0, 0}.SetBit(127, 1),
+			b().Lsh(big.NewInt(1), 127),
+		},
+		{
+			"clear bit",
+			Uint128{0, maxUint64}.SetBit(0, 0),
+			b().Sub(maxU64B, big.NewInt(1)),
+		},
 	} {
 		t.Run(c.name, func(t *testing.T) {
 			i := b().Or(b().Lsh(b().SetUint64(c.expr.H), 64), b().SetUint64(c.expr.L))
@@ -130,3 +194,129 @@ func TestUint128(t *testing.T) {
 		})
 	}
 }
+
+func TestUint128QuoRem(t *testing.T) {
+	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+	toBig := func(u Uint128) *big.Int {
+		return new(big.Int).Or(new(big.Int).Lsh(new(big.Int).SetUint64(u.H), 64), new(big.Int).SetUint64(u.L))
+	}
+	random := func() Uint128 {
+		// vary the magnitudes so that both the 64 bit and 128 bit division paths are exercised
+		return Uint128{r.Uint64(), r.Uint64()}.Rsh(uint(r.Intn(128)))
+	}
+
+	for i := 0; i < 10000; i++ {
+		u, v := random(), random()
+		if v.IsZero() {
+			continue
+		}
+		q, rem := u.QuoRem(v)
+		eQ, eR := new(big.Int).QuoRem(toBig(u), toBig(v), new(big.Int))
+		if toBig(q).Cmp(eQ) != 0 || toBig(rem).Cmp(eR) != 0 {
+			t.Fatalf("%v / %v: expected %v r %v but got %v r %v", toBig(u), toBig(v), eQ, eR, toBig(q), toBig(rem))
+		}
+
+		p := u.Mul(v)
+		eP := new(big.Int).Mul(toBig(u), toBig(v))
+		eP.And(eP, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1)))
+		if toBig(p).Cmp(eP) != 0 {
+			t.Fatalf("%v * %v: expected %v but got %v", toBig(u), toBig(v), eP, toBig(p))
+		}
+	}
+}
+
+func TestUint128Overflow(t *testing.T) {
+	for _, c := range []struct {
+		name     string
+		f        func() (Uint128, bool)
+		expected Uint128
+		overflow bool
+	}{
+		{
+			"add",
+			func() (Uint128, bool) { return Uint128{0, maxUint64}.AddOverflow(Uint128{0, 1}) },
+			Uint128{1, 0},
+			false,
+		},
+		{
+			"add overflow",
+			func() (Uint128, bool) { return Uint128{maxUint64, maxUint64}.AddOverflow(Uint128{0, 2}) },
+			Uint128{0, 1},
+			true,
+		},
+		{
+			"mul",
+			func() (Uint128, bool) { return Uint128{1, maxUint64}.Mul64Overflow(2) },
+			Uint128{3, maxUint64 - 1},
+			false,
+		},
+		{
+			"mul overflow",
+			func() (Uint128, bool) { return Uint128{1 << 63, 0}.Mul64Overflow(2) },
+			Uint128{0, 0},
+			true,
+		},
+		{
+			"mul carry overflow",
+			func() (Uint128, bool) { return Uint128{maxUint64, maxUint64}.Mul64Overflow(maxUint64) },
+			Uint128{maxUint64, 1},
+			true,
+		},
+		{
+			"sub",
+			func() (Uint128, bool) { return Uint128{1, 0}.SubUnderflow(Uint128{0, 1}) },
+			Uint128{0, maxUint64},
+			false,
+		},
+		{
+			"sub underflow",
+			func() (Uint128, bool) { return Uint128{0, 0}.SubUnderflow(Uint128{0, 1}) },
+			Uint128{maxUint64, maxUint64},
+			true,
+		},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			got, overflow := c.f()
+			if got != c.expected || overflow != c.overflow {
+				t.Fatalf("expected (%v, %v) but got (%v, %v)", c.expected, c.overflow, got, overflow)
+			}
+		})
+	}
+}
+
+func TestUint128Bits(t *testing.T) {
+	for _, c := range []struct {
+		name                    string
+		u                       Uint128
+		leading, trailing, ones int
+		zero                    bool
+	}{
+		{"zero", Uint128{0, 0}, 128, 128, 0, true},
+		{"one", Uint128{0, 1}, 127, 0, 1, false},
+		{"high", Uint128{1 << 63, 0}, 0, 127, 1, false},
+		{"max", Uint128{maxUint64, maxUint64}, 0, 0, 128, false},
+		{"mixed", Uint128{1, 1 << 4}, 63, 4, 2, false},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.u.LeadingZeros(); got != c.leading {
+				t.Errorf("expected %v leading zeros but got %v", c.leading, got)
+			}
+			if got := c.u.TrailingZeros(); got != c.trailing {
+				t.Errorf("expected %v trailing zeros but got %v", c.trailing, got)
+			}
+			if got := c.u.OnesCount(); got != c.ones {
+				t.Errorf("expected %v ones but got %v", c.ones, got)
+			}
+			if got := c.u.IsZero(); got != c.zero {
+				t.Errorf("expected zero to be %v but got %v", c.zero, got)
+			}
+			ones := 0
+			for i := 0; i < 128; i++ {
+				ones += int(c.u.Bit(i))
+			}
+			if ones != c.ones {
+				t.Errorf("expected %v bits set but got %v", c.ones, ones)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package ipx
+
+import (
+	"math/bits"
+	"net"
+	"strconv"
+)
+
+// ValueMask is a ternary match on an unsigned integer field such as a port, VLAN or ASN, as used by TCAMs: a number
+// matches when it equals Value in every bit set in Mask.
+type ValueMask struct {
+	Value, Mask uint64
+}
+
+// Contains returns whether n matches.
+func (v ValueMask) Contains(n uint64) bool {
+	return (n^v.Value)&v.Mask == 0
+}
+
+// String returns the value in decimal and the mask in hex separated by a slash, e.g. 1024/0xfc00.
+func (v ValueMask) String() string {
+	return strconv.FormatUint(v.Value, 10) + "/0x" + strconv.FormatUint(v.Mask, 16)
+}
+
+// SummarizeUintRange returns the prefix-aligned value/mask pairs which combined match the numbers between first and
+// last, inclusive, in a field of the given width in bits, e.g. 16 for ports or 12 for VLANs. Each pair matches a
+// block of numbers as a CIDR network does, as TCAM rules are commonly written; a cover using non-contiguous masks may
+// need fewer pairs. It returns nil if width isn't between 1 and 64, first is greater than last, or last doesn't fit in
+// the field.
+func SummarizeUintRange(first, last uint64, width int) []ValueMask {
+	if width < 1 || width > 64 || first > last || (width < 64 && last>>uint(width) != 0) {
+		return nil
+	}
+	fieldMask := uint64(maxUint64) >> uint(64-width)
+
+	var vms []ValueMask
+	for {
+		// as with addresses, the block is as large as the trailing zeros of first allow without passing last
+		size := bits.TrailingZeros64(first)
+		if size > width {
+			size = width
+		}
+		if first != 0 || last != fieldMask { // guard overflow; the block would be the whole field anyway
+			if fit := 63 - bits.LeadingZeros64(last-first+1); fit < size {
+				size = fit
+			}
+		}
+		vms = append(vms, ValueMask{Value: first, Mask: fieldMask &^ (1<<uint(size) - 1)})
+
+		next := first + 1<<uint(size) - 1
+		if next >= last {
+			return vms
+		}
+		first = next + 1
+	}
+}
+
+// ACLEntry matches a network and a range of ports, as a single entry in an access list or TCAM.
+type ACLEntry struct {
+	Net  *net.IPNet
+	Port ValueMask
+}
+
+// String returns the network and port match separated by a space, e.g. 10.0.0.0/8 1024/0xfc00.
+func (e ACLEntry) String() string {
+	return e.Net.String() + " " + e.Port.String()
+}
+
+// ACLEntries returns the entries matching the addresses in nets combined with the ports between firstPort and
+// lastPort, inclusive: each network of the collapsed address set paired with each value/mask pair of the port range.
+// Host bits set in any of the networks are ignored.
+func ACLEntries(nets []*net.IPNet, firstPort, lastPort uint16) []ACLEntry {
+	ports := SummarizeUintRange(uint64(firstPort), uint64(lastPort), 16)
+	collapsed := Collapse(nets)
+
+	entries := make([]ACLEntry, 0, len(collapsed)*len(ports))
+	for _, n := range collapsed {
+		for _, p := range ports {
+			entries = append(entries, ACLEntry{Net: n, Port: p})
+		}
+	}
+	return entries
+}
@@ -0,0 +1,72 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"math/rand"
+	"net"
+	"testing"
+)
+
+func ExampleSummarizeUintRange() {
+	fmt.Println(ipx.SummarizeUintRange(1024, 65535, 16))
+	fmt.Println(ipx.SummarizeUintRange(100, 199, 12))
+	// Output:
+	// [1024/0xfc00 2048/0xf800 4096/0xf000 8192/0xe000 16384/0xc000 32768/0x8000]
+	// [100/0xffc 104/0xff8 112/0xff0 128/0xfc0 192/0xff8]
+}
+
+func ExampleACLEntries() {
+	for _, e := range ipx.ACLEntries([]*net.IPNet{cidr("10.0.0.0/9"), cidr("10.128.0.0/9")}, 8080, 8083) {
+		fmt.Println(e)
+	}
+	// Output:
+	// 10.0.0.0/8 8080/0xfffc
+}
+
+func TestSummarizeUintRange(t *testing.T) {
+	for _, c := range []struct {
+		first, last uint64
+		width       int
+		expected    string
+	}{
+		{0, 65535, 16, "[0/0x0]"},
+		{0, 0, 16, "[0/0xffff]"},
+		{80, 80, 16, "[80/0xffff]"},
+		{0, 1<<64 - 1, 64, "[0/0x0]"},
+		{1 << 63, 1<<64 - 1, 64, "[9223372036854775808/0x8000000000000000]"},
+		{1<<64 - 1, 1<<64 - 1, 64, "[18446744073709551615/0xffffffffffffffff]"},
+		{5, 4, 16, "[]"},
+		{0, 65536, 16, "[]"},
+		{0, 1, 0, "[]"},
+		{0, 1, 65, "[]"},
+	} {
+		t.Run(fmt.Sprint(c.first, "-", c.last, "/", c.width), func(t *testing.T) {
+			if got := fmt.Sprint(ipx.SummarizeUintRange(c.first, c.last, c.width)); got != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestSummarizeUintRangeMatchesExactly(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 200; i++ {
+		width := 1 + r.Intn(12)
+		first := uint64(r.Intn(1 << uint(width)))
+		last := first + uint64(r.Intn(1<<uint(width)-int(first)))
+
+		vms := ipx.SummarizeUintRange(first, last, width)
+		for n := uint64(0); n < 1<<uint(width); n++ {
+			matches := 0
+			for _, vm := range vms {
+				if vm.Contains(n) {
+					matches++
+				}
+			}
+			if expected := n >= first && n <= last; (matches == 1) != expected || matches > 1 {
+				t.Fatalf("%v-%v/%v: %v matched by %v of %v", first, last, width, n, matches, vms)
+			}
+		}
+	}
+}
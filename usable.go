@@ -0,0 +1,105 @@
+package ipx
+
+import (
+	"net"
+	"sort"
+)
+
+// ReservePolicy describes the addresses of a network which are reserved and therefore not usable by hosts.
+type ReservePolicy struct {
+	// Hosts selects the addresses of the network considered before any reservations are applied.
+	Hosts HostsPolicy
+	// Start and End list reserved offsets from the network address and from the last address of the network,
+	// respectively; offsets beyond the size of the network are ignored.
+	Start, End []uint64
+	// Exclude lists ranges of addresses which are never usable. Ranges of the other IP version are ignored.
+	Exclude []IPRange
+}
+
+var (
+	// ReserveAWS matches AWS VPC subnets, where the first four addresses and the last address are reserved.
+	ReserveAWS = ReservePolicy{Hosts: HostsAll, Start: []uint64{0, 1, 2, 3}, End: []uint64{0}}
+	// ReserveGCP matches Google Cloud subnets, where the first two addresses and the last two are reserved.
+	ReserveGCP = ReservePolicy{Hosts: HostsAll, Start: []uint64{0, 1}, End: []uint64{0, 1}}
+)
+
+// UsableHosts returns the addresses of the network which aren't reserved by the policy, in order. Host bits set in
+// ipNet are ignored.
+func UsableHosts(ipNet *net.IPNet, policy ReservePolicy) *ChainIPIter {
+	four := ipNet.IP.To4() != nil
+	spans := usableSpans(ipNet, policy)
+
+	iters := make([]*IPIter, 0, len(spans))
+	for _, s := range spans {
+		if four {
+			iters = append(iters, iterIPv4(uint32(s.first.L), 1, uint32(s.last.L)))
+			continue
+		}
+		iters = append(iters, iterIPv6(s.first, Uint128{0, 1}, s.last))
+	}
+	return ChainIP(iters...)
+}
+
+// UsableCount returns the number of addresses UsableHosts would return.
+func UsableCount(ipNet *net.IPNet, policy ReservePolicy) Uint128 {
+	var count Uint128
+	for _, s := range usableSpans(ipNet, policy) {
+		// a span of every address has a size of 2^128, which wraps to zero
+		var overflow bool
+		if count, overflow = count.AddOverflow(s.last.Minus(s.first).Add(Uint128{0, 1})); overflow || count.IsZero() {
+			return Uint128{maxUint64, maxUint64}
+		}
+	}
+	return count
+}
+
+// usableSpans returns the sorted, disjoint ranges of usable addresses.
+func usableSpans(ipNet *net.IPNet, policy ReservePolicy) []span {
+	four := ipNet.IP.To4() != nil
+	first, last, ok := HostsWithPolicy(ipNet, policy.Hosts).bounds()
+	if !ok {
+		return nil
+	}
+	netFirst, netLast, _ := Addresses(ipNet).bounds()
+	size := netLast.Minus(netFirst)
+
+	var reserved []span
+	for _, off := range policy.Start {
+		if o := (Uint128{0, off}); o.Cmp(size) != 1 {
+			p := netFirst.Add(o)
+			reserved = append(reserved, span{p, p})
+		}
+	}
+	for _, off := range policy.End {
+		if o := (Uint128{0, off}); o.Cmp(size) != 1 {
+			p := netLast.Minus(o)
+			reserved = append(reserved, span{p, p})
+		}
+	}
+	for _, r := range policy.Exclude {
+		if s, rFour, ok := spanOf(r); ok && rFour == four && s.first.Cmp(s.last) != 1 {
+			reserved = append(reserved, s)
+		}
+	}
+	sort.Slice(reserved, func(i, j int) bool {
+		return reserved[i].first.Cmp(reserved[j].first) == -1
+	})
+
+	var spans []span
+	for _, r := range reserved {
+		if r.last.Cmp(first) == -1 {
+			continue
+		}
+		if r.first.Cmp(last) == 1 {
+			break
+		}
+		if r.first.Cmp(first) == 1 {
+			spans = append(spans, span{first, r.first.Minus(Uint128{0, 1})})
+		}
+		if r.last.Cmp(last) != -1 {
+			return spans
+		}
+		first = r.last.Add(Uint128{0, 1})
+	}
+	return append(spans, span{first, last})
+}
@@ -0,0 +1,86 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"testing"
+)
+
+func ExampleUsableHosts() {
+	hosts := ipx.UsableHosts(cidr("10.0.0.0/29"), ipx.ReserveAWS)
+	for hosts.Next() {
+		fmt.Println(hosts.IP())
+	}
+	fmt.Println(ipx.UsableCount(cidr("10.0.0.0/24"), ipx.ReserveAWS))
+	// Output:
+	// 10.0.0.4
+	// 10.0.0.5
+	// 10.0.0.6
+	// 251
+}
+
+func TestUsableHosts(t *testing.T) {
+	for _, c := range []struct {
+		name, net string
+		policy    ipx.ReservePolicy
+		expected  []string
+	}{
+		{"default", "10.0.0.0/29", ipx.ReservePolicy{}, []string{
+			"10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6",
+		}},
+		{"gcp", "10.0.0.0/29", ipx.ReserveGCP, []string{"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5"}},
+		{"gateway", "10.0.0.0/29", ipx.ReservePolicy{Start: []uint64{1}}, []string{
+			"10.0.0.2", "10.0.0.3", "10.0.0.4", "10.0.0.5", "10.0.0.6",
+		}},
+		{"exclude", "10.0.0.0/29", ipx.ReservePolicy{Exclude: []ipx.IPRange{
+			{First: net.ParseIP("10.0.0.3"), Last: net.ParseIP("10.0.0.4")},
+			{First: net.ParseIP("10.0.0.6"), Last: net.ParseIP("10.0.1.0")},
+			{First: net.ParseIP("::"), Last: net.ParseIP("::ffff")},
+		}}, []string{"10.0.0.1", "10.0.0.2", "10.0.0.5"}},
+		{"overlapping", "10.0.0.0/29", ipx.ReservePolicy{
+			Start: []uint64{1, 2},
+			Exclude: []ipx.IPRange{
+				{First: net.ParseIP("10.0.0.0"), Last: net.ParseIP("10.0.0.2")},
+				{First: net.ParseIP("10.0.0.2"), Last: net.ParseIP("10.0.0.3")},
+			},
+		}, []string{"10.0.0.4", "10.0.0.5", "10.0.0.6"}},
+		{"everything", "10.0.0.0/30", ipx.ReservePolicy{Start: []uint64{1, 2, 100}}, nil},
+		{"ipv6", "2001:db8::/125", ipx.ReservePolicy{Start: []uint64{1}, End: []uint64{0}}, []string{
+			"2001:db8::2", "2001:db8::3", "2001:db8::4", "2001:db8::5", "2001:db8::6",
+		}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			var ips []string
+			for iter := ipx.UsableHosts(cidr(c.net), c.policy); iter.Next(); {
+				ips = append(ips, iter.IP().String())
+			}
+			if fmt.Sprint(ips) != fmt.Sprint(c.expected) {
+				t.Fatalf("expected %v but got %v", c.expected, ips)
+			}
+			if count := ipx.UsableCount(cidr(c.net), c.policy); count != (ipx.Uint128{L: uint64(len(ips))}) {
+				t.Fatalf("expected count of %v but got %v", len(ips), count)
+			}
+		})
+	}
+}
+
+func TestUsableCount(t *testing.T) {
+	for _, c := range []struct {
+		net      string
+		policy   ipx.ReservePolicy
+		expected string
+	}{
+		{"10.0.0.0/16", ipx.ReserveAWS, "65531"},
+		{"0.0.0.0/0", ipx.ReservePolicy{Hosts: ipx.HostsAll}, "4294967296"},
+		{"2001:db8::/64", ipx.ReserveAWS, "18446744073709551611"},
+		{"::/0", ipx.ReservePolicy{Hosts: ipx.HostsAll}, "340282366920938463463374607431768211455"},
+		{"::/0", ipx.ReservePolicy{}, "340282366920938463463374607431768211455"},
+	} {
+		t.Run(c.net, func(t *testing.T) {
+			if got := ipx.UsableCount(cidr(c.net), c.policy); got.String() != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package ipx
+
+import (
+	"net"
+	"strconv"
+)
+
+// WalkOrder is the order in which Walk visits subnets.
+type WalkOrder uint8
+
+const (
+	// WalkPreOrder visits each subnet before the subnets within it, depth first.
+	WalkPreOrder WalkOrder = iota
+	// WalkPostOrder visits each subnet after the subnets within it, depth first.
+	WalkPostOrder
+	// WalkBreadthFirst visits every subnet of each prefix length before those of the next.
+	WalkBreadthFirst
+)
+
+// String returns the name of the order, e.g. "pre-order".
+func (o WalkOrder) String() string {
+	switch o {
+	case WalkPreOrder:
+		return "pre-order"
+	case WalkPostOrder:
+		return "post-order"
+	case WalkBreadthFirst:
+		return "breadth-first"
+	}
+	return "WalkOrder(" + strconv.Itoa(int(o)) + ")"
+}
+
+// WalkIter permits iteration over the subnets of a network between two prefix lengths.
+type WalkIter struct {
+	order                 WalkOrder
+	bits, minLen, maxLen  uint8
+	first, last           Uint128 // addresses of the network walked
+	addr                  Uint128
+	prefix                uint8
+	started, done, skip   bool
+	skipped, pendingSkips []span // subtrees skipped during a breadth first walk
+	skipIdx               int
+	net                   *net.IPNet
+}
+
+// Walk returns an iterator over every subnet of ipNet with a prefix length between minPrefix and maxPrefix,
+// inclusive, in the given order. Subnets are visited from the lowest address first at every depth. The iterator does
+// no allocation per step, except to remember skipped subtrees during a breadth first walk. If the prefix lengths are
+// out of order or don't fall between ipNet's prefix length and the length of its addresses, an empty iterator is
+// returned. Host bits set in ipNet are ignored.
+func Walk(ipNet *net.IPNet, minPrefix, maxPrefix int, order WalkOrder) *WalkIter {
+	n := Canonicalize(ipNet)
+	if n == nil {
+		return &WalkIter{done: true}
+	}
+	ones, bits := n.Mask.Size()
+	if minPrefix < ones || maxPrefix < minPrefix || maxPrefix > bits {
+		return &WalkIter{done: true}
+	}
+
+	w := &WalkIter{
+		order:  order,
+		bits:   uint8(bits),
+		minLen: uint8(minPrefix),
+		maxLen: uint8(maxPrefix),
+		net:    &net.IPNet{IP: make(net.IP, bits/8), Mask: make(net.IPMask, bits/8)},
+	}
+	w.first, _ = IPToUint128(n.IP)
+	w.last = w.first.Or(w.hostMask(uint8(ones)))
+	return w
+}
+
+// Net returns the most recent subnet; the underlying type may be modified on later calls to `Next`.
+// It does no allocation.
+func (w *WalkIter) Net() *net.IPNet {
+	return w.net
+}
+
+// SkipChildren prevents the subnets within the most recent subnet from being visited. It has no effect on a post-order
+// walk, which has already visited them.
+func (w *WalkIter) SkipChildren() {
+	switch {
+	case !w.started || w.done || w.prefix == w.maxLen:
+	case w.order == WalkPreOrder:
+		w.skip = true
+	case w.order == WalkBreadthFirst:
+		w.pendingSkips = append(w.pendingSkips, span{w.addr, w.addr.Or(w.hostMask(w.prefix))})
+	}
+}
+
+// Next returns true when the underlying pointer has been successfully updated with the next value.
+func (w *WalkIter) Next() bool {
+	if w.done {
+		return false
+	}
+
+	ok := true
+	switch {
+	case !w.started:
+		w.started = true
+		w.addr, w.prefix = w.first, w.minLen
+		if w.order == WalkPostOrder {
+			w.prefix = w.maxLen
+		}
+	case w.order == WalkPreOrder:
+		ok = w.nextPreOrder()
+	case w.order == WalkPostOrder:
+		ok = w.nextPostOrder()
+	default:
+		ok = w.nextBreadthFirst()
+	}
+	w.skip = false
+	if !ok {
+		w.done = true
+		return false
+	}
+
+	if w.bits == 8*net.IPv4len {
+		from32(uint32(w.addr.L), w.net.IP)
+		from32(uint32(w.hostMask(w.prefix).Not().L), w.net.Mask)
+	} else {
+		From128(w.addr, w.net.IP)
+		From128(w.hostMask(w.prefix).Not(), w.net.Mask)
+	}
+	return true
+}
+
+func (w *WalkIter) nextPreOrder() bool {
+	if w.prefix < w.maxLen && !w.skip {
+		w.prefix++ // the first child shares its parent's address
+		return true
+	}
+	for w.prefix > w.minLen {
+		// the lowest bit of the prefix distinguishes the first child of a parent from the second
+		b := w.hostMask(w.prefix).Add(Uint128{0, 1})
+		if w.addr.And(b).IsZero() {
+			w.addr = w.addr.Or(b)
+			return true
+		}
+		w.addr = w.addr.AndNot(b)
+		w.prefix--
+	}
+	return w.nextRoot()
+}
+
+func (w *WalkIter) nextPostOrder() bool {
+	if w.prefix == w.minLen {
+		if !w.nextRoot() {
+			return false
+		}
+		w.prefix = w.maxLen
+		return true
+	}
+	b := w.hostMask(w.prefix).Add(Uint128{0, 1})
+	if w.addr.And(b).IsZero() {
+		// visit the second child's subtree, starting from its first, deepest subnet
+		w.addr = w.addr.Or(b)
+		w.prefix = w.maxLen
+		return true
+	}
+	w.addr = w.addr.AndNot(b)
+	w.prefix--
+	return true
+}
+
+func (w *WalkIter) nextBreadthFirst() bool {
+	for {
+		if end := w.addr.Or(w.hostMask(w.prefix)); end != w.last {
+			w.addr = end.Add(Uint128{0, 1})
+		} else {
+			if w.prefix == w.maxLen {
+				return false
+			}
+			w.prefix++
+			w.addr = w.first
+			if len(w.pendingSkips) > 0 {
+				w.skipped = mergeSpans(append(w.skipped, w.pendingSkips...))
+				w.pendingSkips = w.pendingSkips[:0]
+			}
+			w.skipIdx = 0
+		}
+
+		for w.skipIdx < len(w.skipped) && w.skipped[w.skipIdx].last.Cmp(w.addr) == -1 {
+			w.skipIdx++
+		}
+		if w.skipIdx == len(w.skipped) || w.skipped[w.skipIdx].first.Cmp(w.addr) == 1 {
+			return true
+		}
+		// jump to the last subnet of the skipped subtree at this depth, so that the next step passes it
+		w.addr = w.skipped[w.skipIdx].last.AndNot(w.hostMask(w.prefix))
+	}
+}
+
+// nextRoot moves to the next subnet at the minimum prefix length, returning false if there are none left.
+func (w *WalkIter) nextRoot() bool {
+	end := w.addr.Or(w.hostMask(w.minLen))
+	if end == w.last {
+		return false
+	}
+	w.addr = end.Add(Uint128{0, 1})
+	return true
+}
+
+// hostMask returns the bits of the addresses which vary within a subnet of the prefix length.
+func (w *WalkIter) hostMask(prefix uint8) Uint128 {
+	return Uint128{0, 1}.Lsh(uint(w.bits - prefix)).Minus(Uint128{0, 1})
+}
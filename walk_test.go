@@ -0,0 +1,132 @@
+package ipx_test
+
+import (
+	"fmt"
+	"github.com/ns1/ipx"
+	"net"
+	"strings"
+	"testing"
+)
+
+func ExampleWalk() {
+	w := ipx.Walk(cidr("10.0.0.0/24"), 24, 26, ipx.WalkPreOrder)
+	for w.Next() {
+		ones, _ := w.Net().Mask.Size()
+		fmt.Println(strings.Repeat("  ", ones-24) + w.Net().String())
+		if w.Net().String() == "10.0.0.0/25" {
+			w.SkipChildren()
+		}
+	}
+	// Output:
+	// 10.0.0.0/24
+	//   10.0.0.0/25
+	//   10.0.0.128/25
+	//     10.0.0.128/26
+	//     10.0.0.192/26
+}
+
+// walkRef walks recursively, in the order expected of Walk.
+func walkRef(n *net.IPNet, minPrefix, maxPrefix int, order ipx.WalkOrder) []string {
+	var out []string
+	var visit func(n *net.IPNet)
+	visit = func(n *net.IPNet) {
+		ones, _ := n.Mask.Size()
+		if ones >= minPrefix && order == ipx.WalkPreOrder {
+			out = append(out, n.String())
+		}
+		if ones < maxPrefix {
+			for s := ipx.Split(n, ones+1); s.Next(); {
+				visit(&net.IPNet{IP: append(net.IP(nil), s.Net().IP...), Mask: s.Net().Mask})
+			}
+		}
+		if ones >= minPrefix && order == ipx.WalkPostOrder {
+			out = append(out, n.String())
+		}
+	}
+	if order == ipx.WalkBreadthFirst {
+		for p := minPrefix; p <= maxPrefix; p++ {
+			for s := ipx.Split(n, p); s.Next(); {
+				out = append(out, s.Net().String())
+			}
+		}
+		return out
+	}
+	visit(n)
+	return out
+}
+
+func TestWalk(t *testing.T) {
+	for _, c := range []struct {
+		net      string
+		min, max int
+	}{
+		{"10.0.0.0/24", 24, 27},
+		{"10.0.0.0/24", 26, 28},
+		{"10.0.0.0/24", 25, 25},
+		{"10.0.0.252/30", 30, 32},
+		{"255.255.255.0/24", 24, 27},
+		{"0.0.0.0/0", 0, 3},
+		{"2001:db8::/120", 121, 124},
+		{"ffff::/16", 16, 19},
+		{"::/0", 1, 3},
+	} {
+		for _, order := range []ipx.WalkOrder{ipx.WalkPreOrder, ipx.WalkPostOrder, ipx.WalkBreadthFirst} {
+			t.Run(fmt.Sprint(c.net, " ", c.min, "-", c.max, " ", order), func(t *testing.T) {
+				var got []string
+				for w := ipx.Walk(cidr(c.net), c.min, c.max, order); w.Next(); {
+					got = append(got, w.Net().String())
+				}
+				if expected := walkRef(cidr(c.net), c.min, c.max, order); fmt.Sprint(got) != fmt.Sprint(expected) {
+					t.Fatalf("expected %v but got %v", expected, got)
+				}
+			})
+		}
+	}
+}
+
+func TestWalkSkipChildren(t *testing.T) {
+	for _, c := range []struct {
+		order    ipx.WalkOrder
+		expected string
+	}{
+		{ipx.WalkPreOrder, "[10.0.0.0/29 10.0.0.0/30 10.0.0.4/30 10.0.0.4/31 10.0.0.4/32 10.0.0.5/32 10.0.0.6/31 10.0.0.6/32 10.0.0.7/32]"},
+		{ipx.WalkBreadthFirst, "[10.0.0.0/29 10.0.0.0/30 10.0.0.4/30 10.0.0.4/31 10.0.0.6/31 10.0.0.4/32 10.0.0.5/32 10.0.0.6/32 10.0.0.7/32]"},
+		{ipx.WalkPostOrder, fmt.Sprint(walkRef(cidr("10.0.0.0/29"), 29, 32, ipx.WalkPostOrder))},
+	} {
+		t.Run(fmt.Sprint(c.order), func(t *testing.T) {
+			var got []string
+			for w := ipx.Walk(cidr("10.0.0.0/29"), 29, 32, c.order); w.Next(); {
+				got = append(got, w.Net().String())
+				if w.Net().String() == "10.0.0.0/30" {
+					w.SkipChildren()
+				}
+			}
+			if fmt.Sprint(got) != c.expected {
+				t.Fatalf("expected %v but got %v", c.expected, got)
+			}
+		})
+	}
+}
+
+func TestWalkInvalid(t *testing.T) {
+	for _, c := range []struct {
+		min, max int
+	}{
+		{23, 25},
+		{26, 25},
+		{24, 33},
+	} {
+		if w := ipx.Walk(cidr("10.0.0.0/24"), c.min, c.max, ipx.WalkPreOrder); w.Next() {
+			t.Errorf("expected empty walk for %v-%v but got %v", c.min, c.max, w.Net())
+		}
+	}
+}
+
+func TestWalkAllocations(t *testing.T) {
+	for _, order := range []ipx.WalkOrder{ipx.WalkPreOrder, ipx.WalkPostOrder, ipx.WalkBreadthFirst} {
+		w := ipx.Walk(cidr("2001:db8::/32"), 32, 48, order)
+		if allocs := testing.AllocsPerRun(1000, func() { w.Next() }); allocs != 0 {
+			t.Errorf("expected no allocations for %v but got %v", order, allocs)
+		}
+	}
+}